@@ -0,0 +1,116 @@
+package parry
+
+import "fmt"
+
+// Option configures a single Expand call.
+type Option func(*Config)
+
+// WithPreserve configures Expand to leave unresolved params untouched
+// instead of expanding them to an empty string. Equivalent to the CLI's
+// -p/--preserve flag.
+func WithPreserve(v bool) Option {
+	return func(c *Config) { c.SetPreserve(v) }
+}
+
+// WithIgnoreQuotes configures Expand to still expand params inside
+// single-quoted regions. Equivalent to the CLI's --ignoreQuotes flag.
+func WithIgnoreQuotes(v bool) Option {
+	return func(c *Config) { c.SetIgnoreQuotes(v) }
+}
+
+// WithLookup overrides the source Expand resolves variables against. By
+// default Expand resolves against the process environment via
+// os.LookupEnv; lookup should follow the same (value string, ok bool)
+// contract.
+func WithLookup(lookup func(name string) (string, bool)) Option {
+	return func(c *Config) { c.SetLookuper(LookupFunc(lookup)) }
+}
+
+// WithLookuper overrides the source Expand resolves variables against,
+// the same as WithLookup but for callers that already have a Lookuper
+// (e.g. a Vault client, or a type merging several layered sources) rather
+// than a bare function.
+func WithLookuper(l Lookuper) Option {
+	return func(c *Config) { c.SetLookuper(l) }
+}
+
+// WithOnUnresolved registers a hook Expand consults for a bare
+// $VAR/${VAR} that has no default and didn't resolve any other way, as a
+// dynamic fallback source (logging, a computed value, a lazily-fetched
+// secret) instead of silently expanding to an empty string. See
+// Config.SetOnUnresolved.
+func WithOnUnresolved(fn func(name string) (string, bool)) Option {
+	return func(c *Config) { c.SetOnUnresolved(fn) }
+}
+
+// WithValueTransform registers a hook Expand runs against every param's
+// resolved value right after resolution, before any built-in transform
+// (--max-value-length, --validate, --number-locale, --quote-for), which
+// all then see fn's result. Use this for an embedder-specific transform --
+// decryption, a secondary lookup, custom formatting -- that should still
+// compose with the built-ins rather than bypass them. See
+// Config.SetValueTransform.
+func WithValueTransform(fn func(name, value string) (string, error)) Option {
+	return func(c *Config) { c.SetValueTransform(fn) }
+}
+
+// Expand runs parry's tokenize -> findParams -> resolve -> reassemble
+// pipeline against input entirely in memory and returns the expanded
+// result. Unlike GetOutput, it never touches os.Stdout or a file, and it
+// returns an error instead of panicking when expansion fails (e.g. an
+// unset ${VAR:?msg} or --fail-on-empty violation).
+func Expand(input string, opts ...Option) (result string, err error) {
+	cfg := NewConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	// The parse path itself no longer panics (see ParseError), but guard
+	// against anything unexpected (e.g. a misbehaving WithLookup) rather
+	// than letting it escape into the caller's process.
+	defer func() {
+		if r := recover(); r != nil {
+			result = ""
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	payload := []rune(input)
+	validSlices, err := getValidSlices(payload, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	allParams, err := findParams(payload)
+	if err != nil {
+		return "", err
+	}
+	params := filterParamsInRange(allParams, validSlices)
+
+	values, err := mapParamValues(params, payload, cfg)
+	if err != nil {
+		return "", err
+	}
+	return reassemble(payload, params, values, cfg)
+}
+
+// FindVariables returns the distinct variable names referenced by input
+// (by either $VAR or ${VAR} form), in order of first appearance. It's a
+// read-only dependency scanner -- no expansion, no environment lookups --
+// meant for cheaply auditing what a template depends on, e.g. across every
+// file in a large tree; unlike calling findParams directly, it shares a
+// single package-level compiled regex across every call instead of
+// recompiling one each time.
+func FindVariables(input string) []string {
+	params, _ := findParams([]rune(input))
+	seen := make(map[string]bool, len(params))
+	var names []string
+	for _, p := range params {
+		if p.Name == "" || seen[p.Name] {
+			continue
+		}
+		seen[p.Name] = true
+		names = append(names, p.Name)
+	}
+	return names
+}
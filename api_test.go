@@ -0,0 +1,197 @@
+package parry
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExpandResolvesAgainstProcessEnvironmentByDefault(t *testing.T) {
+	os.Setenv("EXPAND_VAR", "hello")
+	defer os.Unsetenv("EXPAND_VAR")
+
+	got, err := Expand("$EXPAND_VAR, world")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "hello, world" {
+		t.Fatalf("got %q, want %q", got, "hello, world")
+	}
+}
+
+func TestExpandReturnsErrorInsteadOfPanicking(t *testing.T) {
+	os.Unsetenv("EXPAND_MISSING")
+
+	_, err := Expand("${EXPAND_MISSING:?must be set}")
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func TestExpandWithPreserveLeavesUnresolvedParamsUntouched(t *testing.T) {
+	os.Unsetenv("EXPAND_MISSING")
+
+	got, err := Expand("before $EXPAND_MISSING after", WithPreserve(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "before $EXPAND_MISSING after" {
+		t.Fatalf("got %q, want raw param preserved", got)
+	}
+}
+
+func TestExpandWithLookupUsesCustomSource(t *testing.T) {
+	os.Unsetenv("EXPAND_CUSTOM")
+
+	lookup := func(name string) (string, bool) {
+		if name == "EXPAND_CUSTOM" {
+			return "from-map", true
+		}
+		return "", false
+	}
+
+	got, err := Expand("$EXPAND_CUSTOM", WithLookup(lookup))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "from-map" {
+		t.Fatalf("got %q, want %q", got, "from-map")
+	}
+}
+
+type mapLookuper map[string]string
+
+func (m mapLookuper) Lookup(name string) (string, bool) {
+	v, ok := m[name]
+	return v, ok
+}
+
+func TestExpandWithLookuperUsesCustomSource(t *testing.T) {
+	os.Unsetenv("EXPAND_LOOKUPER")
+
+	got, err := Expand("$EXPAND_LOOKUPER", WithLookuper(mapLookuper{"EXPAND_LOOKUPER": "from-lookuper"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "from-lookuper" {
+		t.Fatalf("got %q, want %q", got, "from-lookuper")
+	}
+}
+
+func TestExpandWithOnUnresolvedSuppliesAValueForAnUnsetVariable(t *testing.T) {
+	os.Unsetenv("EXPAND_UNRESOLVED")
+
+	hook := func(name string) (string, bool) {
+		if name == "EXPAND_UNRESOLVED" {
+			return "computed", true
+		}
+		return "", false
+	}
+
+	got, err := Expand("$EXPAND_UNRESOLVED", WithOnUnresolved(hook))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "computed" {
+		t.Fatalf("got %q, want %q", got, "computed")
+	}
+}
+
+func TestExpandWithOnUnresolvedFallsThroughToPreserveWhenHookDeclines(t *testing.T) {
+	os.Unsetenv("EXPAND_UNRESOLVED_DECLINED")
+
+	hook := func(name string) (string, bool) { return "", false }
+
+	got, err := Expand("$EXPAND_UNRESOLVED_DECLINED", WithOnUnresolved(hook), WithPreserve(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "$EXPAND_UNRESOLVED_DECLINED" {
+		t.Fatalf("got %q, want raw param preserved", got)
+	}
+}
+
+func TestExpandWithOnUnresolvedNotCalledWhenVariableIsSet(t *testing.T) {
+	os.Setenv("EXPAND_ALREADY_SET", "real")
+	defer os.Unsetenv("EXPAND_ALREADY_SET")
+
+	hook := func(name string) (string, bool) {
+		t.Fatalf("hook should not be called for a set variable")
+		return "", false
+	}
+
+	got, err := Expand("$EXPAND_ALREADY_SET", WithOnUnresolved(hook))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "real" {
+		t.Fatalf("got %q, want %q", got, "real")
+	}
+}
+
+func TestExpandWithValueTransformAppliesToMatchingNamesOnly(t *testing.T) {
+	os.Setenv("SHOUT_GREETING", "hello")
+	os.Setenv("QUIET_GREETING", "hello")
+	defer os.Unsetenv("SHOUT_GREETING")
+	defer os.Unsetenv("QUIET_GREETING")
+
+	upperShout := func(name, value string) (string, error) {
+		if strings.HasPrefix(name, "SHOUT_") {
+			return strings.ToUpper(value), nil
+		}
+		return value, nil
+	}
+
+	got, err := Expand("$SHOUT_GREETING $QUIET_GREETING", WithValueTransform(upperShout))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "HELLO hello" {
+		t.Fatalf("got %q, want %q", got, "HELLO hello")
+	}
+}
+
+func TestExpandWithValueTransformComposesWithMaxValueLength(t *testing.T) {
+	os.Setenv("TRANSFORM_LONG", "hi")
+	defer os.Unsetenv("TRANSFORM_LONG")
+
+	repeat := func(name, value string) (string, error) {
+		return value + value + value, nil
+	}
+
+	got, err := Expand("$TRANSFORM_LONG", WithValueTransform(repeat), func(c *Config) { c.maxValueLength = 4 })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "hihi" {
+		t.Fatalf("got %q, want %q", got, "hihi")
+	}
+}
+
+func TestExpandWithValueTransformErrorFailsResolution(t *testing.T) {
+	os.Setenv("TRANSFORM_FAILS", "value")
+	defer os.Unsetenv("TRANSFORM_FAILS")
+
+	failing := func(name, value string) (string, error) {
+		return "", fmt.Errorf("transform rejected %s", name)
+	}
+
+	_, err := Expand("$TRANSFORM_FAILS", WithValueTransform(failing))
+	if err == nil {
+		t.Fatal("expected an error from the failing transform")
+	}
+}
+
+func TestExpandWithIgnoreQuotesExpandsInsideSingleQuotes(t *testing.T) {
+	os.Setenv("EXPAND_QUOTED", "visible")
+	defer os.Unsetenv("EXPAND_QUOTED")
+
+	got, err := Expand("'$EXPAND_QUOTED'", WithIgnoreQuotes(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "'visible'" {
+		t.Fatalf("got %q, want %q", got, "'visible'")
+	}
+}
@@ -0,0 +1,42 @@
+package parry
+
+import "testing"
+
+func TestAtOffsetExpandsOnlyTheCoveringParamLeavingOthersLiteral(t *testing.T) {
+	t.Setenv("AT_OFFSET_FIRST", "one")
+	t.Setenv("AT_OFFSET_SECOND", "two")
+	tmpl := "a=$AT_OFFSET_FIRST b=$AT_OFFSET_SECOND"
+
+	// $AT_OFFSET_SECOND starts right after "a=one b=", i.e. at the "$" in
+	// "b=$AT_OFFSET_SECOND" -- compute its offset from the template itself
+	// so the test doesn't depend on a hand-counted magic number.
+	offset := len("a=$AT_OFFSET_FIRST b=")
+
+	got := runGetOutput(t, tmpl, func(c *Config) {
+		c.SetAt(offset)
+	})
+	want := "a=$AT_OFFSET_FIRST b=two"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestAtOffsetNotCoveredByAnyParamLeavesEverythingLiteral(t *testing.T) {
+	t.Setenv("AT_OFFSET_UNCOVERED", "value")
+	tmpl := "plain text $AT_OFFSET_UNCOVERED"
+
+	got := runGetOutput(t, tmpl, func(c *Config) {
+		c.SetAt(0)
+	})
+	if got != tmpl {
+		t.Fatalf("got %q, want template unchanged: %q", got, tmpl)
+	}
+}
+
+func TestAtNegativeOffsetFailsValidate(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetAt(-5)
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a negative --at offset")
+	}
+}
@@ -0,0 +1,42 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetOutputBaseDirResolvesRelativeEnvfilePath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("BASEDIR_HOST=db01\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("host=$BASEDIR_HOST"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("BASEDIR_HOST")
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetEnvFile(".env")
+	cfg.SetBaseDir(dir)
+
+	if got := captureStdout(t, func() { GetOutput(cfg) }); got != "host=db01" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestResolvePathLeavesAbsoluteAndEmptyPathsAlone(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetBaseDir("/base")
+	if got := resolvePath("", cfg); got != "" {
+		t.Fatalf("got %q, want empty", got)
+	}
+	if got := resolvePath("/abs/path", cfg); got != "/abs/path" {
+		t.Fatalf("got %q, want /abs/path", got)
+	}
+	if got := resolvePath("rel/path", cfg); got != filepath.Join("/base", "rel/path") {
+		t.Fatalf("got %q", got)
+	}
+}
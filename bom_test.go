@@ -0,0 +1,29 @@
+package parry
+
+import "testing"
+
+func withLeadingBOM(s string) string {
+	return string([]byte{0xEF, 0xBB, 0xBF}) + s
+}
+
+func TestStripBOMRemovesLeadingBOMDuringExpansion(t *testing.T) {
+	t.Setenv("BOM_VAR", "value")
+	payload := withLeadingBOM("a=$BOM_VAR\nb=end")
+
+	got := runGetOutput(t, payload, func(c *Config) { c.SetStripBOM(true) })
+	want := "a=value\nb=end"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithoutStripBOMLeadingBOMIsReproduced(t *testing.T) {
+	t.Setenv("BOM_VAR", "value")
+	payload := withLeadingBOM("a=$BOM_VAR\nb=end")
+
+	got := runGetOutput(t, payload, nil)
+	want := withLeadingBOM("a=value\nb=end")
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,443 @@
+// Command parry expands $VAR/${VAR} style parameters in a template file
+// against the process environment.
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/rionpy/parry"
+	"github.com/rionpy/parry/cmd/parry/version"
+)
+
+// Exit codes distinguish why parry failed, so a script can branch on $?
+// instead of scraping stderr. exitGeneric covers a plain usage/argument
+// mistake and any error that doesn't carry one of the classes below.
+const (
+	exitOK               = 0
+	exitGeneric          = 1
+	exitFileNotFound     = 2
+	exitParseError       = 3
+	exitRequiredVariable = 4
+	exitEnvFileError     = 5
+)
+
+// exitCodeFor maps a failure (a recovered panic value or a Validate error)
+// to its exit code, using the ParseError.Class the parry package attaches
+// when the failure falls into one of the documented categories.
+func exitCodeFor(failure any) int {
+	pe, ok := failure.(*parry.ParseError)
+	if !ok {
+		return exitGeneric
+	}
+	switch pe.Class {
+	case parry.ClassFileNotFound:
+		return exitFileNotFound
+	case parry.ClassParse:
+		return exitParseError
+	case parry.ClassRequiredVariable:
+		return exitRequiredVariable
+	case parry.ClassEnvFile:
+		return exitEnvFileError
+	default:
+		return exitGeneric
+	}
+}
+
+func main() {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintln(os.Stderr, "parry:", r)
+			os.Exit(exitCodeFor(r))
+		}
+	}()
+
+	config := parry.NewConfig()
+	args := expandShortFlagClusters(os.Args[1:])
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			for _, file := range args[i+1:] {
+				config.AddFile(file)
+			}
+			break
+		}
+		switch {
+		case arg == "-h" || arg == "--help":
+			printHelp()
+			os.Exit(0)
+		case arg == "-v" || arg == "--version":
+			printVersion()
+			os.Exit(0)
+		case arg == "-i":
+			config.SetEditInPlace(true)
+		case arg == "-p" || arg == "--preserve":
+			config.SetPreserve(true)
+		case strings.HasPrefix(arg, "--prefix="):
+			config.SetPrefix(strings.TrimPrefix(arg, "--prefix="))
+		case arg == "--only":
+			i++
+			config.AddOnly(args[i])
+		case strings.HasPrefix(arg, "--only="):
+			config.AddOnly(strings.TrimPrefix(arg, "--only="))
+		case arg == "--except":
+			i++
+			config.AddExcept(args[i])
+		case strings.HasPrefix(arg, "--except="):
+			config.AddExcept(strings.TrimPrefix(arg, "--except="))
+		case arg == "--ignoreQuotes":
+			config.SetIgnoreQuotes(true)
+		case arg == "--expand-single-quotes":
+			config.SetExpandSingleQuotes(true)
+		case arg == "-l" || arg == "--list":
+			config.SetListMode(true)
+		case arg == "-l=values" || arg == "--list=values":
+			config.SetListMode(true)
+			config.SetListValues(true)
+		case strings.HasPrefix(arg, "--format="):
+			config.SetFormat(strings.TrimPrefix(arg, "--format="))
+		case arg == "--unique":
+			config.SetListUnique(true)
+		case arg == "--missing":
+			config.SetListMissing(true)
+		case arg == "--align":
+			config.SetAlign(true)
+		case arg == "--null-data":
+			config.SetNullData(true)
+		case arg == "--report":
+			config.SetReport(true)
+		case arg == "--highlight":
+			config.SetHighlight(true)
+		case arg == "--color=always":
+			config.SetColorAlways(true)
+		case arg == "--report-unused":
+			config.SetReportUnused(true)
+		case arg == "--fail-on-empty":
+			config.SetFailOnEmpty(true)
+		case arg == "--ensure-final-newline":
+			config.SetEnsureFinalNewline(true)
+		case arg == "--resolve-symlinks":
+			config.SetResolveSymlinks(true)
+		case arg == "--backup":
+			config.SetBackup(true)
+		case strings.HasPrefix(arg, "--backup="):
+			config.SetBackup(true)
+			config.SetBackupSuffix(strings.TrimPrefix(arg, "--backup="))
+		case arg == "--placeholder":
+			config.SetPlaceholder(true)
+		case arg == "--merge-adjacent-placeholders":
+			config.SetMergeAdjacentPlaceholders(true)
+		case arg == "--omit-empty-json":
+			config.SetOmitEmptyJSON(true)
+		case arg == "--dedupe-output-blank-lines":
+			config.SetDedupeOutputBlankLines(true)
+		case arg == "--as-patch":
+			config.SetAsPatch(true)
+		case arg == "--strict":
+			config.SetStrict(true)
+		case arg == "--strict-braces":
+			config.SetStrictBraces(true)
+		case arg == "--collect-errors":
+			config.SetCollectErrors(true)
+		case strings.HasPrefix(arg, "--max-value-length="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--max-value-length="))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "parry: invalid --max-value-length:", err)
+				os.Exit(1)
+			}
+			config.SetMaxValueLength(n)
+		case strings.HasPrefix(arg, "--max-value-length-policy="):
+			config.SetMaxValueLengthPolicy(strings.TrimPrefix(arg, "--max-value-length-policy="))
+		case strings.HasPrefix(arg, "--truncation-marker="):
+			config.SetTruncationMarker(strings.TrimPrefix(arg, "--truncation-marker="))
+		case arg == "--no-env":
+			config.SetNoEnv(true)
+		case arg == "--subst-regex":
+			config.SetSubstRegex(true)
+		case arg == "--convert-eol-only":
+			config.SetConvertEOLOnly(true)
+		case arg == "--strip-bom":
+			config.SetStripBOM(true)
+		case arg == "--write-env":
+			i++
+			config.SetWriteEnv(args[i])
+		case strings.HasPrefix(arg, "--write-env="):
+			config.SetWriteEnv(strings.TrimPrefix(arg, "--write-env="))
+		case arg == "--keep-empty-env-lines":
+			config.SetKeepEmptyEnvLines(true)
+		case arg == "-o" || arg == "--output":
+			i++
+			config.SetOutput(args[i])
+		case strings.HasPrefix(arg, "--output="):
+			config.SetOutput(strings.TrimPrefix(arg, "--output="))
+		case arg == "--tee":
+			i++
+			config.SetTee(args[i])
+		case strings.HasPrefix(arg, "--tee="):
+			config.SetTee(strings.TrimPrefix(arg, "--tee="))
+		case arg == "-e":
+			i++
+			config.AddEnv(args[i])
+		case strings.HasPrefix(arg, "--env="):
+			config.AddEnv(strings.TrimPrefix(arg, "--env="))
+		case arg == "--envfile":
+			i++
+			config.SetEnvFile(args[i])
+		case strings.HasPrefix(arg, "--envfile="):
+			config.SetEnvFile(strings.TrimPrefix(arg, "--envfile="))
+		case arg == "--env-dir":
+			i++
+			config.SetEnvDir(args[i])
+		case strings.HasPrefix(arg, "--env-dir="):
+			config.SetEnvDir(strings.TrimPrefix(arg, "--env-dir="))
+		case arg == "--envfile-if":
+			i++
+			config.SetEnvFileIf(args[i])
+		case strings.HasPrefix(arg, "--envfile-if="):
+			config.SetEnvFileIf(strings.TrimPrefix(arg, "--envfile-if="))
+		case arg == "--envtoml":
+			i++
+			config.SetEnvTOMLFile(args[i])
+		case strings.HasPrefix(arg, "--envtoml="):
+			config.SetEnvTOMLFile(strings.TrimPrefix(arg, "--envtoml="))
+		case strings.HasPrefix(arg, "--toml-table="):
+			config.SetTOMLTable(strings.TrimPrefix(arg, "--toml-table="))
+		case arg == "--kvjson":
+			i++
+			config.SetKVJSONFile(args[i])
+		case strings.HasPrefix(arg, "--kvjson="):
+			config.SetKVJSONFile(strings.TrimPrefix(arg, "--kvjson="))
+		case arg == "--validate":
+			i++
+			config.AddValidate(args[i])
+		case strings.HasPrefix(arg, "--validate="):
+			config.AddValidate(strings.TrimPrefix(arg, "--validate="))
+		case arg == "--params-file":
+			i++
+			config.SetParamsFile(args[i])
+		case strings.HasPrefix(arg, "--params-file="):
+			config.SetParamsFile(strings.TrimPrefix(arg, "--params-file="))
+		case strings.HasPrefix(arg, "--at="):
+			offset, err := strconv.Atoi(strings.TrimPrefix(arg, "--at="))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "parry: invalid --at:", err)
+				os.Exit(1)
+			}
+			config.SetAt(offset)
+		case arg == "--base-dir":
+			i++
+			config.SetBaseDir(args[i])
+		case strings.HasPrefix(arg, "--base-dir="):
+			config.SetBaseDir(strings.TrimPrefix(arg, "--base-dir="))
+		case arg == "--interpret":
+			i++
+			config.SetInterpret(args[i])
+		case strings.HasPrefix(arg, "--interpret="):
+			config.SetInterpret(strings.TrimPrefix(arg, "--interpret="))
+		case strings.HasPrefix(arg, "--quote-for="):
+			config.SetQuoteFor(strings.TrimPrefix(arg, "--quote-for="))
+		case strings.HasPrefix(arg, "--escape-mode="):
+			config.SetEscapeMode(strings.TrimPrefix(arg, "--escape-mode="))
+		case strings.HasPrefix(arg, "--number-locale="):
+			config.SetNumberLocale(strings.TrimPrefix(arg, "--number-locale="))
+		case strings.HasPrefix(arg, "--read-buffer="):
+			size, err := strconv.Atoi(strings.TrimPrefix(arg, "--read-buffer="))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "parry: invalid --read-buffer:", err)
+				os.Exit(1)
+			}
+			config.SetReadBuffer(size)
+		case strings.HasPrefix(arg, "--max-file-size="):
+			size, err := strconv.ParseInt(strings.TrimPrefix(arg, "--max-file-size="), 10, 64)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "parry: invalid --max-file-size:", err)
+				os.Exit(1)
+			}
+			config.SetMaxFileSize(size)
+		default:
+			config.AddFile(arg)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "parry:", err)
+		os.Exit(exitCodeFor(err))
+	}
+
+	parry.GetOutput(config)
+}
+
+// boolShortFlags are the single-dash flags expandShortFlagClusters can
+// expand anywhere in a cluster, since they take no value.
+var boolShortFlags = map[byte]string{
+	'h': "-h",
+	'v': "-v",
+	'i': "-i",
+	'p': "-p",
+	'l': "-l",
+}
+
+// valueShortFlags are the single-dash flags that consume the next arg as
+// their value, so expandShortFlagClusters only allows them as the last
+// character of a cluster.
+var valueShortFlags = map[byte]string{
+	'o': "-o",
+	'e': "-e",
+}
+
+// expandShortFlagClusters rewrites clustered short flags like "-lp" into
+// "-l", "-p" so the main switch below (which only ever compares a whole
+// arg) recognizes each one. Anything that isn't a valid cluster --
+// "--long" flags, a bare "-x", "-l=values", or a cluster misusing a
+// value-taking flag anywhere but last -- passes through untouched.
+func expandShortFlagClusters(args []string) []string {
+	out := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !isShortFlagCluster(arg) {
+			out = append(out, arg)
+			continue
+		}
+		for i := 1; i < len(arg); i++ {
+			if f, ok := boolShortFlags[arg[i]]; ok {
+				out = append(out, f)
+				continue
+			}
+			out = append(out, valueShortFlags[arg[i]])
+		}
+	}
+	return out
+}
+
+// isShortFlagCluster reports whether arg is two or more single-dash short
+// flags run together, e.g. "-lp" or "-le" (but not "-el", since "-e"
+// would then be missing its value).
+func isShortFlagCluster(arg string) bool {
+	if len(arg) < 3 || arg[0] != '-' || arg[1] == '-' {
+		return false
+	}
+	for i := 1; i < len(arg); i++ {
+		c := arg[i]
+		if _, ok := boolShortFlags[c]; ok {
+			continue
+		}
+		if _, ok := valueShortFlags[c]; ok && i == len(arg)-1 {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// flagHelp describes one command-line flag for printHelp. It's the single
+// source of truth for the help text, so a flag gains a help entry the
+// moment it's added here, instead of the usage text drifting out of sync
+// with the switch above.
+type flagHelp struct {
+	flag string
+	help string
+}
+
+var flagHelps = []flagHelp{
+	{"-l, --list", "list the params found in the template instead of expanding it"},
+	{"--list=values", "like --list, but also include each param's resolved value"},
+	{"--format json|csv|tab|name-value|plain|yaml", "select --list's output format (default json)"},
+	{"--unique", "collapse --list output to one entry per distinct param, with an occurrence count"},
+	{"--missing", "filter --list output down to variables that are currently unset and have no default"},
+	{"-p, --preserve", "leave unresolved params untouched instead of expanding to empty string"},
+	{"--prefix NAME", "only expand params whose name starts with NAME; leave every other param as literal text"},
+	{"--only NAME[,NAME...]", "only expand the named params; leave every other param as literal text, repeatable"},
+	{"--except NAME[,NAME...]", "expand every param except the named ones, which are left as literal text, repeatable"},
+	{"--placeholder", "expand an unset $VAR/${VAR} to <NAME> instead of empty string"},
+	{"--merge-adjacent-placeholders", "with --placeholder, combine a run of adjacent markers into one, e.g. <A,B>"},
+	{"--ignoreQuotes", "expand params inside single-quoted regions too"},
+	{"--expand-single-quotes", "like --ignoreQuotes for single quotes only, keeping the quote characters in output"},
+	{"-i", "edit the template file in place instead of writing to stdout"},
+	{"-o, --output FILE", "write expanded output to FILE instead of stdout"},
+	{"--tee FILE", "write expanded output to both stdout and FILE"},
+	{"-e, --env NAME=value", "set an environment override before expansion (repeatable)"},
+	{"--envfile FILE", "load NAME=value assignments from FILE before expansion"},
+	{"--env-dir DIR", "load every *.env in DIR in lexical order, each layering over the last; each file's base name also becomes a ${@profile:NAME:VAR} profile"},
+	{"--envfile-if NAME=value:FILE", "load FILE like --envfile, but only when NAME currently equals value"},
+	{"--base-dir DIR", "resolve relative template/--envfile/--envtoml paths against DIR"},
+	{"--envtoml FILE", "load environment assignments from a TOML file's scalars"},
+	{"--toml-table NAME", "with --envtoml, load only the given sub-table"},
+	{"--kvjson FILE", "consult a flat JSON object as a lower-precedence fallback for unset variables, read once and cached"},
+	{"--validate NAME=pattern", "require NAME's resolved value to match pattern, repeatable"},
+	{"--params-file PATH", "declare expected vars' description/required/default/validate via a JSON or YAML schema"},
+	{"--at=OFFSET", "resolve only the param whose span contains the rune OFFSET, leaving the rest literal"},
+	{"--interpret posix|bash", "restrict expansion to a dialect (bash is the default)"},
+	{"--quote-for DIALECT", "escape each resolved value for a config dialect, e.g. ini, yaml, make, prometheus"},
+	{"--escape-mode c|json|none", "select ${VAR@E}'s escape grammar (default c)"},
+	{"--number-locale TAG", "reformat a resolved value's digit grouping/decimal separator for a BCP 47 locale (e.g. de), numeric values only"},
+	{"--strict", "fail, listing every undefined variable, instead of expanding to empty string"},
+	{"--strict-braces", "fail on a bare $VAR reference, requiring every reference to use ${VAR} form"},
+	{"--collect-errors", "report every \"?\"/\":?\" failure together instead of stopping at the first"},
+	{"--max-value-length N", "cap a single resolved value to N runes (0, the default, is unlimited)"},
+	{"--max-value-length-policy truncate|error", "what to do to a value over the limit (default truncate)"},
+	{"--truncation-marker TEXT", "append TEXT to a value --max-value-length truncates"},
+	{"--no-env", "resolve only from --envfile/--envtoml/-e, ignoring the inherited process environment"},
+	{"--subst-regex", "treat ${VAR/old/new}'s old as a regex, letting new reference \\1, \\2, ... capturing groups"},
+	{"--convert-eol-only", "convert CRLF to LF and skip param expansion entirely"},
+	{"--strip-bom", "remove a leading UTF-8 byte order mark from the input before processing"},
+	{"--write-env FILE", "write every referenced variable and its resolved value to FILE in .env format"},
+	{"--as-patch", "emit a unified diff turning the template into its rendered output"},
+	{"--keep-empty-env-lines", "expand an env file as an env file: comments and blank lines round-trip untouched"},
+	{"--omit-empty-json", "drop a \"key\": \"$VAR\" JSON line entirely when $VAR resolved to empty"},
+	{"--dedupe-output-blank-lines", "collapse runs of 3+ consecutive blank lines in the rendered output to one"},
+	{"--fail-on-empty", "error when a bare $VAR/${VAR} is set but empty"},
+	{"--align", "pad columns in --list's tab/name-value output"},
+	{"--null-data", "treat NUL-delimited records as single logical lines"},
+	{"--report", "trace each param's resolution across every environment source"},
+	{"--highlight", "print the template unexpanded, with each param wrapped in color codes on a terminal"},
+	{"--color=always", "force --highlight's color codes on even when stdout isn't a terminal"},
+	{"--report-unused", "after expansion, print to stderr every -e/--envfile-provided variable the template never referenced"},
+	{"--ensure-final-newline", "append a trailing newline to the output if it's missing one"},
+	{"--resolve-symlinks", "with -i, write through a symlinked target to its real path"},
+	{"--backup[=SUFFIX]", "with -i, copy the original to file+SUFFIX (default .bak) before replacing it"},
+	{"--read-buffer N", "set the stdin read chunk size, in bytes"},
+	{"--max-file-size N", "reject an input or env file larger than N bytes"},
+	{"--", "treat every remaining argument as a filename, even one starting with -"},
+	{"-h, --help", "print this help and exit"},
+	{"-v, --version", "print version, commit, and build date, then exit"},
+}
+
+func printHelp() {
+	fmt.Println("Usage: parry [flags] [file...]")
+	fmt.Println()
+	fmt.Println("Expand $VAR/${VAR} style parameters in one or more template files (or")
+	fmt.Println("stdin, with no files given) against the environment, writing the result")
+	fmt.Println("to stdout.")
+	fmt.Println()
+	fmt.Println("Flags:")
+	for _, fh := range flagHelps {
+		fmt.Printf("  %-22s %s\n", fh.flag, fh.help)
+	}
+	fmt.Println()
+	fmt.Println("Exit codes:")
+	fmt.Println("  0  success")
+	fmt.Println("  1  usage error or an error without a more specific code below")
+	fmt.Println("  2  a template, env, kvjson, or params file does not exist")
+	fmt.Println("  3  a parse/quote error, or a value rejected by --validate/--max-value-length")
+	fmt.Println("  4  a required variable (\"?\"/\":?\", --strict, or --params-file's required) was unset")
+	fmt.Println("  5  malformed content inside an --envfile/--envtoml/--kvjson file")
+	fmt.Println()
+	fmt.Println("Examples:")
+	fmt.Println("  parry config.tmpl > config.yml")
+	fmt.Println("  cat config.tmpl | parry > config.yml")
+	fmt.Println("  parry -i config.yml")
+	fmt.Println("  parry --envfile .env config.tmpl")
+}
+
+// printVersion prints build metadata set at link time via -ldflags -X on
+// the version package, plus the Go runtime version the binary was built
+// with, so a bug report captures enough to reproduce it.
+func printVersion() {
+	fmt.Printf("parry %s\n", version.Version)
+	fmt.Printf("commit:  %s\n", version.Commit)
+	fmt.Printf("built:   %s\n", version.Date)
+	fmt.Printf("go:      %s\n", runtime.Version())
+}
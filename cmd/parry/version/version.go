@@ -0,0 +1,16 @@
+// Package version holds build metadata for the parry binary. Version,
+// Commit, and Date are meant to be set at build time via:
+//
+//	go build -ldflags "-X github.com/rionpy/parry/cmd/parry/version.Version=v1.2.3 \
+//	  -X github.com/rionpy/parry/cmd/parry/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/rionpy/parry/cmd/parry/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Left unset, a dev build reports "dev"/"unknown" rather than an empty
+// string.
+package version
+
+var (
+	Version = "dev"
+	Commit  = "unknown"
+	Date    = "unknown"
+)
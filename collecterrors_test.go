@@ -0,0 +1,68 @@
+package parry
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCollectErrorsReportsEveryFailedRequiredVariable(t *testing.T) {
+	os.Unsetenv("COLLECT_ERR_A")
+	os.Unsetenv("COLLECT_ERR_B")
+	t.Setenv("COLLECT_ERR_OK", "1")
+
+	cfg := NewConfig()
+	cfg.SetCollectErrors(true)
+	_, err := mustExpandErr(t, "a=${COLLECT_ERR_A:?missing a} b=${COLLECT_ERR_B:?missing b} c=$COLLECT_ERR_OK", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if !strings.Contains(pe.Error(), "missing a") || !strings.Contains(pe.Error(), "missing b") {
+		t.Fatalf("expected both failures in error, got %q", pe.Error())
+	}
+}
+
+func TestCollectErrorsMessageDoesNotDuplicateFailures(t *testing.T) {
+	os.Unsetenv("COLLECT_ERR_DUP")
+
+	cfg := NewConfig()
+	cfg.SetCollectErrors(true)
+	_, err := mustExpandErr(t, "a=${COLLECT_ERR_DUP:?missing dup}", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if n := strings.Count(pe.Error(), "missing dup"); n != 1 {
+		t.Fatalf("expected \"missing dup\" to appear once in %q, got %d times", pe.Error(), n)
+	}
+}
+
+func TestCollectErrorsPassesWhenEveryRequiredVariableIsSet(t *testing.T) {
+	t.Setenv("COLLECT_ERR_SET", "1")
+
+	cfg := NewConfig()
+	cfg.SetCollectErrors(true)
+	got, err := mustExpandErr(t, "v=${COLLECT_ERR_SET:?must be set}", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "v=1" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestWithoutCollectErrorsOnlyFirstFailureIsReported(t *testing.T) {
+	os.Unsetenv("COLLECT_ERR_FIRST")
+	os.Unsetenv("COLLECT_ERR_SECOND")
+
+	cfg := NewConfig()
+	_, err := mustExpandErr(t, "a=${COLLECT_ERR_FIRST:?first missing} b=${COLLECT_ERR_SECOND:?second missing}", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if strings.Contains(pe.Message, "second missing") {
+		t.Fatalf("expected only the first failure without --collect-errors, got %q", pe.Message)
+	}
+}
@@ -0,0 +1,62 @@
+package parry
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestExpandConcurrentIgnoreQuotesSettingsDontRace runs Expand with
+// ignoreQuotes true and false in parallel goroutines, each against its own
+// Config (there is no shared package-level ignoreQuotes state to race on),
+// and checks each gets the result for its own setting. Run with -race to
+// verify.
+func TestExpandConcurrentIgnoreQuotesSettingsDontRace(t *testing.T) {
+	t.Setenv("CONCURRENCY_VAR", "visible")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 200)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			got, err := Expand("'$CONCURRENCY_VAR'", WithIgnoreQuotes(true))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if got != "'visible'" {
+				errs <- errAssert("ignoreQuotes=true", got, "'visible'")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			got, err := Expand("'$CONCURRENCY_VAR'", WithIgnoreQuotes(false))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if got != "'$CONCURRENCY_VAR'" {
+				errs <- errAssert("ignoreQuotes=false", got, "'$CONCURRENCY_VAR'")
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+type assertError struct {
+	label, got, want string
+}
+
+func (e *assertError) Error() string {
+	return e.label + ": got " + e.got + ", want " + e.want
+}
+
+func errAssert(label, got, want string) error {
+	return &assertError{label: label, got: got, want: want}
+}
@@ -0,0 +1,957 @@
+package parry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dlclark/regexp2"
+	"golang.org/x/text/language"
+)
+
+// Config holds all the settings that drive a single parry run.
+type Config struct {
+	files        []string
+	editInPlace  bool
+	preserve     bool
+	ignoreQuotes bool
+
+	// prefix, when set, scopes expansion to params whose name starts with
+	// it -- any other param is passed through as literal text, resolved
+	// or not. See --prefix and resolveParam's prefix check.
+	prefix string
+
+	// highlight and colorAlways back --highlight/--color=always: highlight
+	// prints the template unexpanded with each param occurrence wrapped in
+	// ANSI color codes instead of resolving anything, so a user can see at
+	// a glance where variables live in a file. colorAlways forces the color
+	// codes on even when stdout isn't a terminal. See highlightParams.
+	highlight   bool
+	colorAlways bool
+
+	// only and except back --only/--except: when only is non-empty,
+	// expansion is scoped to just those names; except instead excludes
+	// the named ones from an otherwise-normal expansion. A name excluded
+	// either way is reproduced verbatim, like --preserve for that name.
+	// Both compose with --prefix; see mapParamValues.
+	only   []string
+	except []string
+
+	// expandSingleQuotes makes single-quoted regions eligible for param
+	// expansion, like ignoreQuotes, but keeps the quote characters in the
+	// output instead of treating quoting as irrelevant everywhere.
+	expandSingleQuotes bool
+
+	envFile      string
+	envDir       string
+	envFileIf    string
+	envOverrides []string
+
+	// profiles holds, for each *.env file --env-dir loaded, its own values
+	// keyed by the file's base name without the ".env" extension (e.g.
+	// "prod.env" -> "prod"), independent of the merged environment those
+	// files are also applied to. ${@profile:NAME:VAR} resolves against
+	// this map instead of the normal precedence chain. Populated by
+	// GetOutput while loading --env-dir; nil otherwise.
+	profiles map[string]map[string]string
+
+	// envTOMLFile and tomlTable back --envtoml/--toml-table: envTOMLFile
+	// loads a TOML document's scalars as environment assignments, scoped
+	// to tomlTable's sub-table when tomlTable is non-empty.
+	envTOMLFile string
+	tomlTable   string
+
+	// interpret selects the expansion dialect: "" and "bash" (the default)
+	// allow the full operator set, while "posix" restricts expansion to
+	// the operators POSIX itself defines and rejects bash/parry extensions
+	// (substring, replace, case conversion, suffix/prefix stripping,
+	// indirection, and ${@prefix:...}).
+	interpret string
+
+	// quoteFor selects a config-dialect escaping pass applied to each
+	// resolved value before reassembly, e.g. "ini", "yaml", "make",
+	// "prometheus". Empty means no escaping.
+	quoteFor string
+
+	// escapeMode selects which escape-sequence grammar ${VAR@E} interprets:
+	// "" and "c" (the default) are escapeHandler's backslash n/t/r/\\/"/'
+	// handling, "json" is the full JSON string escape grammar, and "none"
+	// leaves the value untouched.
+	escapeMode string
+
+	// numberLocale selects a BCP 47 locale (e.g. "de") whose digit grouping
+	// and decimal separator --number-locale reformats a resolved value
+	// with, but only when the value parses cleanly as a number. Empty
+	// means no reformatting.
+	numberLocale string
+
+	// readBuffer is the chunk size used when streaming input from stdin, in
+	// bytes. 0 means use defaultReadBufferSize.
+	readBuffer int
+
+	// lookuper, when set (e.g. via Expand's WithLookup/WithLookuper
+	// options), replaces the process environment as the source of variable
+	// values. All variable resolution routes through it via lookupVar, so
+	// swapping it out (a map, a Vault client, a layered merge of files) is
+	// enough to expand without touching process-global state.
+	lookuper Lookuper
+
+	// operandCache memoizes default-operand evaluation within a single run;
+	// see resolveCachedOperand.
+	operandCache map[string]string
+
+	// assignedVars holds names assigned in-template via "=" / ":=" (see
+	// handleDefaults) while a custom lookuper is set. lookupVar consults
+	// it first, ahead of lookuper itself, so the assignment is visible to
+	// later references in the same run -- without it, a custom Lookuper
+	// would never see the assignment, since (unlike the default
+	// os.Setenv-backed path) it has no process environment to read it
+	// back from. nil when no custom lookuper is in play, since that path
+	// still goes through os.Setenv directly.
+	assignedVars map[string]string
+
+	// valueTransform, when set, runs right after a param resolves to its
+	// raw value and before any built-in transform (--max-value-length,
+	// --validate, --number-locale, --quote-for), which all then see its
+	// result instead of the original. See SetValueTransform.
+	valueTransform func(name, value string) (string, error)
+
+	// onUnresolved, when set, is consulted for a bare $VAR/${VAR} that
+	// lookupVar couldn't resolve, before --placeholder/--preserve/the
+	// empty-string default apply. Its bool return works like Lookuper's:
+	// true means use the returned string, false means fall through to
+	// normal behavior. See SetOnUnresolved.
+	onUnresolved func(name string) (string, bool)
+
+	listMode    bool
+	listValues  bool
+	listUnique  bool
+	listMissing bool
+	format      string // "json" (default), "csv", "tab", "name-value", "plain", "yaml"
+	align       bool
+
+	// nullData treats NUL-delimited records (or a whole file with none) as
+	// a single logical line, for find -print0-style input.
+	nullData bool
+
+	// report toggles --report, which traces each param's resolution across
+	// every environment source instead of expanding the template.
+	report bool
+
+	// reportUnused toggles --report-unused, which prints, to stderr after
+	// expansion finishes, every name provided via -e/--envfile/--envtoml/
+	// --env-dir/--envfile-if that no processed template ever referenced.
+	reportUnused bool
+
+	// failOnEmpty makes a bare $VAR/${VAR} (no default operator) error out
+	// when the variable is set but empty, instead of expanding to "".
+	failOnEmpty bool
+
+	// ensureFinalNewline appends a trailing "\n" to the output if it's
+	// missing one. Default behavior is byte-exact.
+	ensureFinalNewline bool
+
+	// resolveSymlinks makes -i write through a symlinked target to its
+	// resolved real path, so the symlink itself is never replaced.
+	resolveSymlinks bool
+
+	// backup makes -i copy the file's pre-render bytes to backupSuffix
+	// (".bak" if empty) before replacing it. See --backup/--backup=SUFFIX.
+	backup       bool
+	backupSuffix string
+
+	// omitEmptyJSON drops `"key": "$VAR"` lines whose $VAR resolved to the
+	// empty string, instead of emitting `"key": ""`.
+	omitEmptyJSON bool
+
+	// dedupeOutputBlankLines collapses runs of 3 or more consecutive blank
+	// lines in the rendered output down to a single blank line, cleaning up
+	// generated config where many optional sections collapsed to nothing.
+	dedupeOutputBlankLines bool
+
+	// maxFileSize rejects an input or env file larger than this many bytes,
+	// checked via os.Stat before a full read and by counting bytes read
+	// during a streaming read. 0 means unlimited.
+	maxFileSize int64
+
+	// output, when set, is the path GetOutput writes rendered output to
+	// instead of os.Stdout. Mutually exclusive with editInPlace, since
+	// both target a file.
+	output string
+
+	// teePath, when set, is an additional path GetOutput writes rendered
+	// output to, alongside os.Stdout -- like the tee command. Mutually
+	// exclusive with editInPlace and output, since both of those already
+	// repurpose "the normal output" away from plain stdout, making "also
+	// tee it to stdout" ambiguous.
+	teePath string
+
+	// asPatch makes GetOutput write a unified diff turning the template
+	// into its rendered output, instead of the rendered output itself.
+	asPatch bool
+
+	// strict makes resolution fail with every undefined variable listed,
+	// instead of silently expanding each to "". See checkStrict for which
+	// operators are exempt because they already supply their own fallback.
+	strict bool
+
+	// keepEmptyEnvLines treats the template as an env file rather than
+	// free-form text: only the value half of each assignment line is
+	// expanded, and every other line (comments, blank lines) is kept
+	// verbatim. See expandEnvFormat.
+	keepEmptyEnvLines bool
+
+	// baseDir, when set, is the directory relative paths to template,
+	// --envfile, and --envtoml files are resolved against, instead of the
+	// process's working directory. Makes a render reproducible regardless
+	// of where parry is actually invoked from. See resolvePath.
+	baseDir string
+
+	// noEnv makes GetOutput resolve variables only against --envfile/
+	// --envtoml/-e sources, ignoring the inherited process environment
+	// entirely, for reproducible rendering. See GetOutput's noEnv branch.
+	noEnv bool
+
+	// substRegex makes `${VAR/old/new}` treat old as a regex pattern
+	// instead of a glob, and allows new to reference old's capturing
+	// groups with `\1`, `\2`, etc. See replaceGlob.
+	substRegex bool
+
+	// convertEOLOnly makes processFile perform only CRLF-to-LF conversion,
+	// skipping tokenization and param expansion entirely. See convertEOL.
+	convertEOLOnly bool
+
+	// stripBOM makes readBytesOrStdin remove a leading UTF-8 byte order
+	// mark from a file or stdin payload before it's processed, so a
+	// template exported from an editor that writes one doesn't carry it
+	// into the rendered output. Off by default to keep parry's default
+	// behavior byte-exact; see --strip-bom.
+	stripBOM bool
+
+	// writeEnv, when set, is a path to write a companion .env snapshot to
+	// after expansion: every variable the template referenced, paired
+	// with its resolved value. See writeEnvFile.
+	writeEnv string
+
+	// placeholder makes a bare $VAR/${VAR} with no default operator expand
+	// to "<NAME>" instead of "" when the variable is unset, so a rendered
+	// template makes an unresolved reference visible rather than silently
+	// blank. See --placeholder.
+	placeholder bool
+
+	// mergeAdjacentPlaceholders, when placeholder is also set, collapses a
+	// run of textually adjacent placeholder markers (e.g. "${A}${B}" both
+	// unset) into a single combined marker ("<A,B>") instead of leaving
+	// "<A><B>". See --merge-adjacent-placeholders and reassemble.
+	mergeAdjacentPlaceholders bool
+
+	// kvJSONFile backs --kvjson FILE: a flat JSON object consulted by
+	// lookupVar as a lower-precedence fallback when a name isn't found in
+	// the environment/lookuper, instead of being loaded into the
+	// environment itself like --envfile/--envtoml are. See kvJSONLookup.
+	kvJSONFile   string
+	kvJSONValues map[string]string
+	kvJSONLoaded bool
+
+	// strictBraces makes a bare $VAR reference an error instead of
+	// expanding it, requiring every reference to use ${VAR} form. See
+	// --strict-braces and checkStrictBraces.
+	strictBraces bool
+
+	// maxValueLength caps a single resolved value's length in runes. 0
+	// means unlimited. maxValueLengthPolicy selects what happens when a
+	// value exceeds it: "" and "truncate" (the default) cut it to the
+	// limit and append truncationMarker; "error" fails resolution instead.
+	// See --max-value-length and enforceMaxValueLength.
+	maxValueLength       int
+	maxValueLengthPolicy string
+	truncationMarker     string
+
+	// validateSpecs holds each repeatable --validate 'NAME=pattern' flag
+	// verbatim, parsed and compiled into validatePatterns by Validate. A
+	// resolved value whose variable has an entry here must match the
+	// pattern or resolution fails. See --validate.
+	validateSpecs    []string
+	validatePatterns map[string]*regexp2.Regexp
+
+	// paramsFile backs --params-file PATH: a JSON or YAML document (YAML
+	// when path ends in .yaml/.yml, JSON otherwise) declaring each
+	// expected variable's metadata as a ParamSchema. Validate parses it
+	// once into paramsSchema and folds each entry's Required flag and
+	// Validate pattern into the same requiredParams/validatePatterns
+	// checks --require and --validate would populate on their own, and
+	// paramsFileLookup then serves Default as lookupVar's lowest-
+	// precedence fallback, below --kvjson.
+	paramsFile     string
+	paramsSchema   map[string]ParamSchema
+	requiredParams map[string]bool
+
+	// atOffset backs --at OFFSET: a rune offset into the payload. When set
+	// (>= 0), only the single param whose Position contains it is resolved
+	// -- every other param, and all surrounding text, passes through
+	// literally. -1 (the default, see NewConfig) means unset. See
+	// paramAtOffset.
+	atOffset int
+
+	// collectErrors toggles --collect-errors: every "?"/":?" param in the
+	// payload is resolved up front and every failure is reported together
+	// in one error, instead of mapParamValues/reassemble stopping at the
+	// first. See checkErrorOperators.
+	collectErrors bool
+}
+
+// NewConfig returns a Config with the documented defaults.
+func NewConfig() *Config {
+	return &Config{format: "json", atOffset: -1}
+}
+
+// AddFile appends a template file to read from. With no files added,
+// GetOutput reads a single template from stdin; with one or more, it
+// processes each independently, in the order added.
+func (c *Config) AddFile(file string) {
+	c.files = append(c.files, file)
+}
+
+// SetEditInPlace toggles -i edit-in-place mode.
+func (c *Config) SetEditInPlace(v bool) {
+	c.editInPlace = v
+}
+
+// SetPreserve toggles -p/--preserve mode, which leaves unresolved params
+// untouched instead of expanding them to empty string.
+func (c *Config) SetPreserve(v bool) {
+	c.preserve = v
+}
+
+// SetOnUnresolved registers a hook consulted for a bare $VAR/${VAR} that
+// has no default and didn't resolve any other way: fn receives the
+// variable's name and, like a Lookuper, returns its value and whether it
+// found one. A false return falls through to --placeholder/--preserve/the
+// empty-string default exactly as if no hook were set. Use this to log an
+// unresolved reference or serve a computed value from a dynamic source.
+func (c *Config) SetOnUnresolved(fn func(name string) (string, bool)) {
+	c.onUnresolved = fn
+}
+
+// SetValueTransform registers a hook run against every param's resolved
+// value (encryption, a lookup, custom formatting) right after resolution,
+// before any built-in transform -- --max-value-length, --validate,
+// --number-locale, and --quote-for all see fn's result rather than the
+// original, so it composes with them instead of bypassing them. fn is
+// skipped for a param left literal by --prefix/--only/--except. An error
+// from fn fails resolution the same as a built-in transform's error would.
+func (c *Config) SetValueTransform(fn func(name, value string) (string, error)) {
+	c.valueTransform = fn
+}
+
+// SetHighlight toggles --highlight, which prints the template unexpanded
+// with each param occurrence wrapped in ANSI color codes instead of
+// resolving anything.
+func (c *Config) SetHighlight(v bool) {
+	c.highlight = v
+}
+
+// SetColorAlways toggles --color=always, which forces --highlight's color
+// codes on even when stdout isn't a terminal (e.g. piping into `less -R`).
+func (c *Config) SetColorAlways(v bool) {
+	c.colorAlways = v
+}
+
+// AddOnly records one or more comma-separated names for --only, repeatable
+// to build up the list across several flags. Once set, expansion is scoped
+// to just these names; every other param is reproduced verbatim.
+func (c *Config) AddOnly(spec string) {
+	c.only = append(c.only, strings.Split(spec, ",")...)
+}
+
+// AddExcept records one or more comma-separated names for --except,
+// repeatable to build up the list across several flags. A named param is
+// reproduced verbatim instead of being expanded; every other param expands
+// normally.
+func (c *Config) AddExcept(spec string) {
+	c.except = append(c.except, strings.Split(spec, ",")...)
+}
+
+// SetPrefix scopes expansion to params whose name starts with prefix,
+// passing every other param through as literal text -- a scoped version
+// of --preserve that doesn't require those other params to be unresolved.
+// Pass "" to expand every param again.
+func (c *Config) SetPrefix(prefix string) {
+	c.prefix = prefix
+}
+
+// SetPlaceholder toggles --placeholder, which expands an unset bare
+// $VAR/${VAR} to "<NAME>" instead of "".
+func (c *Config) SetPlaceholder(v bool) {
+	c.placeholder = v
+}
+
+// SetMergeAdjacentPlaceholders toggles --merge-adjacent-placeholders, which
+// collapses a run of textually adjacent --placeholder markers into one
+// combined marker. Has no effect unless placeholder is also set.
+func (c *Config) SetMergeAdjacentPlaceholders(v bool) {
+	c.mergeAdjacentPlaceholders = v
+}
+
+// SetIgnoreQuotes toggles whether single-quoted regions are still eligible
+// for expansion.
+func (c *Config) SetIgnoreQuotes(v bool) {
+	c.ignoreQuotes = v
+}
+
+// SetExpandSingleQuotes toggles --expand-single-quotes, which treats
+// single-quoted regions as eligible for param expansion, like
+// SetIgnoreQuotes, but keeps the quote characters themselves in the output.
+func (c *Config) SetExpandSingleQuotes(v bool) {
+	c.expandSingleQuotes = v
+}
+
+// SetEnvFile records a path to load environment assignments from.
+func (c *Config) SetEnvFile(path string) {
+	c.envFile = path
+}
+
+// SetEnvFileIf records a --envfile-if spec ("NAME=value:path"), loaded the
+// same way --envfile is, but only once the named condition holds against
+// the environment --envfile/--envtoml/--env-dir have populated so far.
+func (c *Config) SetEnvFileIf(spec string) {
+	c.envFileIf = spec
+}
+
+// SetEnvDir records a directory whose *.env files --env-dir loads in
+// lexical order, each layering over the last -- so "00-base.env" sets
+// defaults and "10-prod.env" overrides them.
+func (c *Config) SetEnvDir(dir string) {
+	c.envDir = dir
+}
+
+// SetKVJSONFile records a flat JSON object file as a lower-precedence
+// value source: a name not found in the environment falls through to this
+// file's keys before resolving as unset. Unlike SetEnvFile/SetEnvTOMLFile,
+// these values are never written to the process environment, and the file
+// is read at most once, lazily, the first time a lookup actually needs it.
+func (c *Config) SetKVJSONFile(path string) {
+	c.kvJSONFile = path
+}
+
+// AddValidate records a repeatable --validate 'NAME=pattern' spec: once
+// compiled by Validate, a referenced NAME's resolved value must match
+// pattern or resolution fails with the variable name and the failing value.
+func (c *Config) AddValidate(spec string) {
+	c.validateSpecs = append(c.validateSpecs, spec)
+}
+
+// AddEnv records a `NAME=value` override to apply before expansion.
+func (c *Config) AddEnv(assignment string) {
+	c.envOverrides = append(c.envOverrides, assignment)
+}
+
+// SetEnvTOMLFile records a path to load environment assignments from a
+// TOML document's scalars.
+func (c *Config) SetEnvTOMLFile(path string) {
+	c.envTOMLFile = path
+}
+
+// SetTOMLTable restricts --envtoml to a single top-level sub-table,
+// instead of the document's top-level scalars.
+func (c *Config) SetTOMLTable(table string) {
+	c.tomlTable = table
+}
+
+// SetInterpret records the requested expansion dialect: "bash" (the
+// default) or "posix". See the interpret field doc for what "posix"
+// restricts.
+func (c *Config) SetInterpret(dialect string) {
+	c.interpret = dialect
+}
+
+// maxReadBufferSize bounds --read-buffer so a typo (or hostile input)
+// can't trigger an unbounded allocation.
+const maxReadBufferSize = 64 << 20 // 64 MiB
+
+// SetReadBuffer sets the chunk size, in bytes, used when streaming input
+// from stdin. 0 (the default) means use defaultReadBufferSize.
+func (c *Config) SetReadBuffer(size int) {
+	c.readBuffer = size
+}
+
+// readBufferSize returns the configured read-buffer size, or
+// defaultReadBufferSize if none was set.
+func (c *Config) readBufferSize() int {
+	if c.readBuffer <= 0 {
+		return defaultReadBufferSize
+	}
+	return c.readBuffer
+}
+
+// SetEscapeMode selects which escape-sequence grammar ${VAR@E} interprets:
+// "c" (the default), "json", or "none". See the escapeMode field doc.
+func (c *Config) SetEscapeMode(mode string) {
+	c.escapeMode = mode
+}
+
+// SetNumberLocale selects a BCP 47 locale (e.g. "de") whose digit grouping
+// and decimal separator --number-locale reformats a numeric resolved value
+// with. See the numberLocale field doc.
+func (c *Config) SetNumberLocale(locale string) {
+	c.numberLocale = locale
+}
+
+// SetQuoteFor selects a config-dialect escaping pass (e.g. "ini", "yaml")
+// applied to each resolved value before reassembly.
+func (c *Config) SetQuoteFor(dialect string) {
+	c.quoteFor = dialect
+}
+
+// SetListMode toggles -l/--list mode.
+func (c *Config) SetListMode(v bool) {
+	c.listMode = v
+}
+
+// SetFormat selects the --list output format.
+func (c *Config) SetFormat(format string) {
+	c.format = format
+}
+
+// SetListValues toggles --list=values, including each param's resolved
+// value (or an explicit unset sentinel) in list output.
+func (c *Config) SetListValues(v bool) {
+	c.listValues = v
+}
+
+// SetListUnique toggles --unique, collapsing --list output to one entry per
+// distinct Param.Id (sorted by its raw text) with a Count of how many times
+// it was referenced, instead of one entry per occurrence.
+func (c *Config) SetListUnique(v bool) {
+	c.listUnique = v
+}
+
+// SetListMissing toggles --missing, filtering --list output down to the
+// distinct variable names that are currently unset and have no operator
+// supplying a fallback.
+func (c *Config) SetListMissing(v bool) {
+	c.listMissing = v
+}
+
+// SetAlign toggles --align column padding for the tab/name-value list
+// formats.
+func (c *Config) SetAlign(v bool) {
+	c.align = v
+}
+
+// SetNullData toggles --null-data, treating NUL-delimited records (or the
+// whole input, if there are none) as a single logical line.
+func (c *Config) SetNullData(v bool) {
+	c.nullData = v
+}
+
+// SetReport toggles --report, a full precedence trace per variable across
+// every environment source.
+func (c *Config) SetReport(v bool) {
+	c.report = v
+}
+
+// SetReportUnused toggles --report-unused, which lists every -e/--envfile-
+// provided variable a run never referenced.
+func (c *Config) SetReportUnused(v bool) {
+	c.reportUnused = v
+}
+
+// SetFailOnEmpty toggles --fail-on-empty, erroring when a bare reference
+// to a variable resolves to a set-but-empty value.
+func (c *Config) SetFailOnEmpty(v bool) {
+	c.failOnEmpty = v
+}
+
+// SetEnsureFinalNewline toggles --ensure-final-newline, appending a
+// trailing "\n" to the output when it's missing one.
+func (c *Config) SetEnsureFinalNewline(v bool) {
+	c.ensureFinalNewline = v
+}
+
+// SetResolveSymlinks toggles --resolve-symlinks, making -i resolve a
+// symlinked target to its real path before writing, so the symlink itself
+// is never replaced.
+func (c *Config) SetResolveSymlinks(v bool) {
+	c.resolveSymlinks = v
+}
+
+// SetBackup toggles --backup: -i copies the file's pre-render bytes to a
+// backup path before replacing it. See SetBackupSuffix for the suffix.
+func (c *Config) SetBackup(v bool) {
+	c.backup = v
+}
+
+// SetBackupSuffix records --backup=SUFFIX's suffix. An empty suffix (the
+// default, from a bare --backup) means ".bak".
+func (c *Config) SetBackupSuffix(suffix string) {
+	c.backupSuffix = suffix
+}
+
+// SetMaxFileSize sets the largest input or env file, in bytes, parry will
+// read. 0 (the default) means unlimited.
+func (c *Config) SetMaxFileSize(size int64) {
+	c.maxFileSize = size
+}
+
+// SetOutput sets the path GetOutput writes rendered output to, in place
+// of os.Stdout. Cannot be combined with SetEditInPlace(true).
+func (c *Config) SetOutput(path string) {
+	c.output = path
+}
+
+// SetTee sets a path GetOutput writes rendered output to in addition to
+// os.Stdout. Cannot be combined with SetOutput or SetEditInPlace(true).
+func (c *Config) SetTee(path string) {
+	c.teePath = path
+}
+
+// SetParamsFile records a path to a JSON or YAML document (see
+// parseParamsFile) declaring each expected variable's metadata as a
+// ParamSchema. Validate parses it once, at which point a Required entry
+// joins the --strict-style required check and a Validate entry joins
+// --validate's pattern checks; a Default serves as the lowest-precedence
+// fallback lookupVar falls through to, below --kvjson.
+func (c *Config) SetParamsFile(path string) {
+	c.paramsFile = path
+}
+
+// SetAt records --at's rune offset: resolution then touches only the
+// single param covering that offset, leaving every other param and all
+// surrounding text literal. A negative offset is rejected by Validate.
+func (c *Config) SetAt(offset int) {
+	c.atOffset = offset
+}
+
+// SetCollectErrors toggles --collect-errors: instead of resolution stopping
+// at the first "?"/":?" failure, every such param in the payload is
+// resolved up front and every failure is reported together in one error.
+func (c *Config) SetCollectErrors(v bool) {
+	c.collectErrors = v
+}
+
+// SetOmitEmptyJSON toggles --omit-empty-json, dropping a simple
+// `"key": "$VAR"` line from the output entirely when $VAR resolved to the
+// empty string, instead of emitting `"key": ""`.
+func (c *Config) SetOmitEmptyJSON(v bool) {
+	c.omitEmptyJSON = v
+}
+
+// SetDedupeOutputBlankLines toggles --dedupe-output-blank-lines, collapsing
+// runs of 3 or more consecutive blank lines in the rendered output down to
+// a single blank line.
+func (c *Config) SetDedupeOutputBlankLines(v bool) {
+	c.dedupeOutputBlankLines = v
+}
+
+// SetAsPatch toggles --as-patch, making GetOutput write a unified diff
+// turning the template into its rendered output, instead of the rendered
+// output itself.
+func (c *Config) SetAsPatch(v bool) {
+	c.asPatch = v
+}
+
+// SetStrict toggles --strict: resolution fails up front, listing every
+// undefined variable, instead of silently expanding each to "".
+func (c *Config) SetStrict(v bool) {
+	c.strict = v
+}
+
+// SetStrictBraces toggles --strict-braces: a bare $VAR reference fails
+// resolution with its position instead of being expanded, requiring every
+// reference in the template to use ${VAR} form.
+func (c *Config) SetStrictBraces(v bool) {
+	c.strictBraces = v
+}
+
+// SetMaxValueLength caps a single resolved value's length in runes; 0
+// (the default) means unlimited. See SetMaxValueLengthPolicy for what
+// happens when a value exceeds it.
+func (c *Config) SetMaxValueLength(n int) {
+	c.maxValueLength = n
+}
+
+// SetMaxValueLengthPolicy selects what --max-value-length does to a value
+// over the limit: "" and "truncate" (the default) cut it to the limit and
+// append SetTruncationMarker's marker; "error" fails resolution instead,
+// reporting the param's position.
+func (c *Config) SetMaxValueLengthPolicy(policy string) {
+	c.maxValueLengthPolicy = policy
+}
+
+// SetTruncationMarker sets the text appended to a value --max-value-length
+// truncates under the "truncate" policy. Empty (the default) appends
+// nothing.
+func (c *Config) SetTruncationMarker(marker string) {
+	c.truncationMarker = marker
+}
+
+// SetKeepEmptyEnvLines toggles --keep-empty-env-lines: the template is
+// expanded as an env file rather than free-form text, so comment and
+// blank lines round-trip untouched instead of being scanned for params.
+func (c *Config) SetKeepEmptyEnvLines(v bool) {
+	c.keepEmptyEnvLines = v
+}
+
+// SetBaseDir sets the directory relative template, --envfile, and
+// --envtoml paths are resolved against. Unset (the default) means the
+// process's working directory, as before.
+func (c *Config) SetBaseDir(dir string) {
+	c.baseDir = dir
+}
+
+// SetNoEnv toggles --no-env: resolution draws only from --envfile/
+// --envtoml/-e sources, never from the inherited process environment.
+func (c *Config) SetNoEnv(v bool) {
+	c.noEnv = v
+}
+
+// SetSubstRegex toggles --subst-regex: `${VAR/old/new}` treats old as a
+// regex pattern instead of a glob, with new able to reference old's
+// capturing groups via `\1`, `\2`, etc.
+func (c *Config) SetSubstRegex(v bool) {
+	c.substRegex = v
+}
+
+// SetConvertEOLOnly toggles --convert-eol-only: processFile performs only
+// CRLF-to-LF line-ending conversion, skipping param expansion entirely.
+func (c *Config) SetConvertEOLOnly(v bool) {
+	c.convertEOLOnly = v
+}
+
+// SetWriteEnv records a path to write a companion .env snapshot of every
+// referenced variable and its resolved value to, after expansion.
+func (c *Config) SetWriteEnv(path string) {
+	c.writeEnv = path
+}
+
+// SetStripBOM toggles --strip-bom, which removes a leading UTF-8 byte
+// order mark from a file or stdin payload before it's processed. Works
+// standalone, or combine with --convert-eol-only for a dos2unix-style pass.
+func (c *Config) SetStripBOM(v bool) {
+	c.stripBOM = v
+}
+
+// Lookuper resolves a variable by name, reporting whether it was found.
+// It has the same contract as os.LookupEnv: implement it over a map, a
+// Vault client, a layered merge of env files, or anything else variable
+// values might come from.
+type Lookuper interface {
+	Lookup(name string) (string, bool)
+}
+
+// LookupFunc adapts a plain function to the Lookuper interface.
+type LookupFunc func(name string) (string, bool)
+
+// Lookup calls f.
+func (f LookupFunc) Lookup(name string) (string, bool) { return f(name) }
+
+// osEnvLookuper is the default Lookuper, backed by the process environment.
+type osEnvLookuper struct{}
+
+func (osEnvLookuper) Lookup(name string) (string, bool) { return os.LookupEnv(name) }
+
+// SetLookuper overrides the source c resolves variables against. The
+// default, used when none is set, resolves against the process
+// environment via os.LookupEnv.
+func (c *Config) SetLookuper(l Lookuper) {
+	c.lookuper = l
+}
+
+// lookupVar resolves name against c.lookuper if one was set, falling back
+// to the process environment otherwise. Every variable resolution in the
+// package routes through this method rather than calling os.LookupEnv
+// directly, so a Config with a custom Lookuper never touches process
+// environment state. assignedVars, when populated, is checked first so an
+// in-template "=" / ":=" assignment (see handleDefaults) is visible to
+// later references even when a custom Lookuper is set.
+func (c *Config) lookupVar(name string) (string, bool) {
+	if c != nil && c.assignedVars != nil {
+		if value, ok := c.assignedVars[name]; ok {
+			return value, true
+		}
+	}
+	var value string
+	var ok bool
+	if c != nil && c.lookuper != nil {
+		value, ok = c.lookuper.Lookup(name)
+	} else {
+		value, ok = osEnvLookuper{}.Lookup(name)
+	}
+	if ok || c == nil {
+		return value, ok
+	}
+	if c.kvJSONFile != "" {
+		if value, ok = c.kvJSONLookup(name); ok {
+			return value, ok
+		}
+	}
+	return c.paramsFileDefault(name)
+}
+
+// lookupProfileVar resolves name against the named profile's own layer
+// (see the profiles field), ignoring the active environment's precedence
+// entirely. A profile that was never loaded via --env-dir has no entry and
+// always misses.
+func (c *Config) lookupProfileVar(profile, name string) (string, bool) {
+	if c == nil || c.profiles == nil {
+		return "", false
+	}
+	value, ok := c.profiles[profile][name]
+	return value, ok
+}
+
+// kvJSONLookup lazily loads and caches --kvjson's flat JSON object the
+// first time it's needed, so a template that never falls through to it
+// never pays the cost of reading or parsing the file. Non-string values
+// are stringified with fmt.Sprint, the same as structLookup.
+func (c *Config) kvJSONLookup(name string) (string, bool) {
+	if !c.kvJSONLoaded {
+		c.kvJSONLoaded = true
+		data, err := os.ReadFile(resolvePath(c.kvJSONFile, c))
+		if err != nil {
+			panic(wrapFileError(c.kvJSONFile, err))
+		}
+		var raw map[string]any
+		if err := json.Unmarshal(data, &raw); err != nil {
+			panic(&ParseError{Fragment: c.kvJSONFile, Position: -1, Message: "invalid --kvjson file: " + err.Error(), Class: ClassEnvFile})
+		}
+		values := make(map[string]string, len(raw))
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				values[k] = s
+			} else {
+				values[k] = fmt.Sprint(v)
+			}
+		}
+		c.kvJSONValues = values
+	}
+	value, ok := c.kvJSONValues[name]
+	return value, ok
+}
+
+// paramsFileDefault serves a --params-file Default as lookupVar's
+// lowest-precedence fallback, once a name hasn't been found anywhere else.
+// An entry with no Default is treated the same as no entry at all.
+func (c *Config) paramsFileDefault(name string) (string, bool) {
+	schema, ok := c.paramsSchema[name]
+	if !ok || schema.Default == "" {
+		return "", false
+	}
+	return schema.Default, true
+}
+
+// Validate checks the configuration for obvious mistakes before a run
+// begins.
+func (c *Config) Validate() error {
+	for _, file := range c.files {
+		info, err := os.Stat(resolvePath(file, c))
+		if err != nil {
+			switch {
+			case errors.Is(err, os.ErrNotExist):
+				return &ParseError{Fragment: file, Position: -1, Message: "file does not exist: " + file, Class: ClassFileNotFound}
+			case errors.Is(err, os.ErrPermission):
+				return fmt.Errorf("permission denied reading file: %s", file)
+			default:
+				return err
+			}
+		}
+		if info.IsDir() {
+			return &ParseError{Fragment: file, Position: -1, Message: "file is a directory, not a file: " + file, Class: ClassFileNotFound}
+		}
+	}
+	if c.envDir != "" {
+		info, err := os.Stat(resolvePath(c.envDir, c))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return &ParseError{Fragment: c.envDir, Position: -1, Message: "env-dir does not exist: " + c.envDir, Class: ClassFileNotFound}
+			}
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("env-dir is not a directory: %s", c.envDir)
+		}
+	}
+	switch c.format {
+	case "json", "csv", "tab", "name-value", "plain", "yaml":
+	default:
+		return fmt.Errorf("unknown list format: %s", c.format)
+	}
+	switch c.quoteFor {
+	case "", "ini", "yaml", "make", "prometheus":
+	default:
+		return fmt.Errorf("unknown quote-for dialect: %s", c.quoteFor)
+	}
+	switch c.interpret {
+	case "", "posix", "bash":
+	default:
+		return fmt.Errorf("unknown interpret dialect: %s", c.interpret)
+	}
+	switch c.escapeMode {
+	case "", "c", "json", "none":
+	default:
+		return fmt.Errorf("unknown escape-mode: %s", c.escapeMode)
+	}
+	if c.numberLocale != "" {
+		if _, err := language.Parse(c.numberLocale); err != nil {
+			return fmt.Errorf("unknown --number-locale: %s", c.numberLocale)
+		}
+	}
+	switch c.maxValueLengthPolicy {
+	case "", "truncate", "error":
+	default:
+		return fmt.Errorf("unknown max-value-length policy: %s", c.maxValueLengthPolicy)
+	}
+	if c.maxValueLength < 0 {
+		return fmt.Errorf("max-value-length must not be negative, got %d", c.maxValueLength)
+	}
+	if c.readBuffer != 0 && (c.readBuffer < 1 || c.readBuffer > maxReadBufferSize) {
+		return fmt.Errorf("read-buffer must be between 1 and %d bytes, got %d", maxReadBufferSize, c.readBuffer)
+	}
+	if c.atOffset < -1 {
+		return fmt.Errorf("--at offset must not be negative, got %d", c.atOffset)
+	}
+	if c.editInPlace && len(c.files) == 0 {
+		return fmt.Errorf("cannot edit stdin in place, -i requires at least one file")
+	}
+	if len(c.validateSpecs) > 0 {
+		c.validatePatterns = make(map[string]*regexp2.Regexp, len(c.validateSpecs))
+		for _, spec := range c.validateSpecs {
+			name, pattern, found := strings.Cut(spec, "=")
+			if !found {
+				return fmt.Errorf("--validate expects NAME=pattern, got %q", spec)
+			}
+			re, err := regexp2.Compile(pattern, 0)
+			if err != nil {
+				return fmt.Errorf("--validate %s: invalid pattern: %w", name, err)
+			}
+			c.validatePatterns[name] = re
+		}
+	}
+	if c.paramsFile != "" {
+		schema, err := parseParamsFile(resolvePath(c.paramsFile, c))
+		if err != nil {
+			return err
+		}
+		c.paramsSchema = schema
+		c.requiredParams = make(map[string]bool, len(schema))
+		if c.validatePatterns == nil {
+			c.validatePatterns = make(map[string]*regexp2.Regexp)
+		}
+		for name, s := range schema {
+			if s.Required {
+				c.requiredParams[name] = true
+			}
+			if s.Validate != "" {
+				re, err := regexp2.Compile(s.Validate, 0)
+				if err != nil {
+					return fmt.Errorf("--params-file %s: invalid validate pattern for %s: %w", c.paramsFile, name, err)
+				}
+				c.validatePatterns[name] = re
+			}
+		}
+	}
+	return nil
+}
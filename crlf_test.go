@@ -0,0 +1,55 @@
+package parry
+
+import "testing"
+
+func TestTokenizeByQuotesPreservesCRLF(t *testing.T) {
+	payload := []rune("a=\"quoted\r\nvalue\" b=unquoted\r\nc")
+	segments, err := tokenizeByQuotes(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var rebuilt []rune
+	for _, seg := range segments {
+		rebuilt = append(rebuilt, payload[seg.Start:seg.End]...)
+	}
+	if string(rebuilt) != string(payload) {
+		t.Fatalf("got %q, want %q", string(rebuilt), string(payload))
+	}
+}
+
+func TestFindParamsLocatesParamsAcrossCRLFLines(t *testing.T) {
+	payload := []rune("first=$FIRST\r\nsecond=$SECOND\r\n")
+	params, err := findParams(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(params) != 2 {
+		t.Fatalf("got %d params, want 2", len(params))
+	}
+	if params[0].Name != "FIRST" || params[1].Name != "SECOND" {
+		t.Fatalf("got names %q, %q", params[0].Name, params[1].Name)
+	}
+}
+
+func TestGetOutputPreservesCRLFInUnexpandedRegions(t *testing.T) {
+	t.Setenv("CRLF_VAR", "value")
+	got := runGetOutput(t, "a=$CRLF_VAR\r\nb=literal\r\nc=end", nil)
+	want := "a=value\r\nb=literal\r\nc=end"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseEnvFileStripsTrailingCRFromValues(t *testing.T) {
+	path := writeParamsFile(t, "crlf.env", "DIR=/opt/app\r\nLOG=${DIR}/log\r\n")
+	values, err := parseEnvFile(path, false, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if values["DIR"] != "/opt/app" {
+		t.Fatalf("got DIR=%q, want %q", values["DIR"], "/opt/app")
+	}
+	if values["LOG"] != "/opt/app/log" {
+		t.Fatalf("got LOG=%q, want %q", values["LOG"], "/opt/app/log")
+	}
+}
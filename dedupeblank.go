@@ -0,0 +1,19 @@
+package parry
+
+import (
+	"regexp"
+)
+
+// runOfBlankLines matches 3 or more consecutive blank lines (each just
+// optional trailing whitespace) -- i.e. 4 or more consecutive line breaks --
+// the threshold --dedupe-output-blank-lines collapses down to a single
+// blank line.
+var runOfBlankLines = regexp.MustCompile(`\n(?:[ \t]*\n){3,}`)
+
+// dedupeBlankLines collapses every run of 3 or more consecutive blank lines
+// in output down to a single blank line. It's a final text transform run
+// only on the rendered output, never on the original template, so it can't
+// interfere with expansion itself.
+func dedupeBlankLines(output string) string {
+	return runOfBlankLines.ReplaceAllString(output, "\n\n")
+}
@@ -0,0 +1,44 @@
+package parry
+
+import "testing"
+
+func TestDedupeOutputBlankLinesCollapsesLongRun(t *testing.T) {
+	tmpl := "first\n\n\n\n\nsecond\n"
+	got := runGetOutput(t, tmpl, func(c *Config) {
+		c.SetDedupeOutputBlankLines(true)
+	})
+	want := "first\n\nsecond\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestDedupeOutputBlankLinesLeavesShortRunsAlone(t *testing.T) {
+	tmpl := "first\n\n\nsecond\n"
+	got := runGetOutput(t, tmpl, func(c *Config) {
+		c.SetDedupeOutputBlankLines(true)
+	})
+	if got != tmpl {
+		t.Fatalf("got %q, want %q (2 blank lines is below the 3+ threshold)", got, tmpl)
+	}
+}
+
+func TestDedupeOutputBlankLinesCollapsesAfterExpansion(t *testing.T) {
+	t.Setenv("DEDUPE_UNSET_A", "")
+	tmpl := "keep\n$DEDUPE_UNSET_A\n\n\n\nend\n"
+	got := runGetOutput(t, tmpl, func(c *Config) {
+		c.SetDedupeOutputBlankLines(true)
+	})
+	want := "keep\n\nend\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestDedupeOutputBlankLinesOffByDefault(t *testing.T) {
+	tmpl := "first\n\n\n\n\nsecond\n"
+	got := runGetOutput(t, tmpl, func(c *Config) {})
+	if got != tmpl {
+		t.Fatalf("got %q, want %q (no transform without the flag)", got, tmpl)
+	}
+}
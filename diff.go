@@ -0,0 +1,201 @@
+package parry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines unifiedDiff keeps around
+// each change, matching the `diff -u`/git default.
+const contextLines = 3
+
+// diffOp is one run of a unified diff: a contiguous range of equal,
+// deleted (present only in a), or inserted (present only in b) lines.
+type diffOp struct {
+	kind         byte // 'e' equal, 'd' delete, 'i' insert
+	aStart, aEnd int
+	bStart, bEnd int
+}
+
+// diffLines computes the opcodes turning a's lines into b's lines via an
+// O(len(a)*len(b)) longest-common-subsequence table. That's fine for the
+// template-sized inputs --as-patch targets; it isn't meant for diffing
+// multi-megabyte files.
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	push := func(kind byte, aStart, aEnd, bStart, bEnd int) {
+		if len(ops) > 0 && ops[len(ops)-1].kind == kind {
+			ops[len(ops)-1].aEnd = aEnd
+			ops[len(ops)-1].bEnd = bEnd
+			return
+		}
+		ops = append(ops, diffOp{kind: kind, aStart: aStart, aEnd: aEnd, bStart: bStart, bEnd: bEnd})
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			push('e', i, i+1, j, j+1)
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			push('d', i, i+1, j, j)
+			i++
+		default:
+			push('i', i, i, j, j+1)
+			j++
+		}
+	}
+	for i < n {
+		push('d', i, i+1, j, j)
+		i++
+	}
+	for j < m {
+		push('i', i, i, j, j+1)
+		j++
+	}
+	return ops
+}
+
+// hunk is a group of diffOps rendered as one "@@ ... @@" block.
+type hunk struct {
+	ops []diffOp
+}
+
+// groupHunks merges diffLines' opcodes into hunks, trimming long equal
+// runs down to contextLines at each edge and splitting a hunk wherever an
+// equal run is long enough to separate two changes by more than
+// 2*contextLines, the same grouping diff -u uses.
+func groupHunks(ops []diffOp) []hunk {
+	if len(ops) == 0 || (len(ops) == 1 && ops[0].kind == 'e') {
+		return nil
+	}
+
+	var hunks []hunk
+	var cur []diffOp
+	for idx, op := range ops {
+		if op.kind != 'e' {
+			cur = append(cur, op)
+			continue
+		}
+		length := op.aEnd - op.aStart
+		switch {
+		case len(cur) == 0:
+			if length > contextLines {
+				op.aStart, op.bStart = op.aEnd-contextLines, op.bEnd-contextLines
+			}
+			cur = append(cur, op)
+		case idx == len(ops)-1:
+			if length > contextLines {
+				op.aEnd, op.bEnd = op.aStart+contextLines, op.bStart+contextLines
+			}
+			cur = append(cur, op)
+			hunks = append(hunks, hunk{ops: cur})
+			cur = nil
+		case length > 2*contextLines:
+			closing := op
+			closing.aEnd, closing.bEnd = closing.aStart+contextLines, closing.bStart+contextLines
+			cur = append(cur, closing)
+			hunks = append(hunks, hunk{ops: cur})
+
+			opening := op
+			opening.aStart, opening.bStart = opening.aEnd-contextLines, opening.bEnd-contextLines
+			cur = []diffOp{opening}
+		default:
+			cur = append(cur, op)
+		}
+	}
+	if len(cur) > 0 {
+		hunks = append(hunks, hunk{ops: cur})
+	}
+	return hunks
+}
+
+// hunkRange formats one side of an "@@ ... @@" header per the unified
+// diff convention: a single line number when count is 1, omitted entirely
+// in favor of the preceding line when count is 0.
+func hunkRange(start, count int) string {
+	line := start + 1
+	if count == 0 {
+		line = start
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+func formatHunk(h hunk, aLines, bLines []string) string {
+	aStart, aEnd := h.ops[0].aStart, h.ops[len(h.ops)-1].aEnd
+	bStart, bEnd := h.ops[0].bStart, h.ops[len(h.ops)-1].bEnd
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(aStart, aEnd-aStart), hunkRange(bStart, bEnd-bStart))
+	for _, op := range h.ops {
+		switch op.kind {
+		case 'e':
+			for k := op.aStart; k < op.aEnd; k++ {
+				b.WriteString(" " + aLines[k])
+			}
+		case 'd':
+			for k := op.aStart; k < op.aEnd; k++ {
+				b.WriteString("-" + aLines[k])
+			}
+		case 'i':
+			for k := op.bStart; k < op.bEnd; k++ {
+				b.WriteString("+" + bLines[k])
+			}
+		}
+	}
+	return b.String()
+}
+
+// splitDiffLines splits s into lines, each keeping its own trailing "\n"
+// (so formatHunk doesn't need to add one back), dropping the empty
+// trailing element strings.SplitAfter leaves after a final newline.
+func splitDiffLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	return lines
+}
+
+// unifiedDiff renders a git-style unified diff turning before into after,
+// with path used in the "--- a/path"/"+++ b/path" file headers. It
+// returns "" when before and after are identical.
+func unifiedDiff(path, before, after string) string {
+	aLines := splitDiffLines(before)
+	bLines := splitDiffLines(after)
+	hunks := groupHunks(diffLines(aLines, bLines))
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n", path)
+	fmt.Fprintf(&b, "+++ b/%s\n", path)
+	for _, h := range hunks {
+		b.WriteString(formatHunk(h, aLines, bLines))
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package parry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnifiedDiffAppliesCleanlyWithPatch(t *testing.T) {
+	if _, err := exec.LookPath("patch"); err != nil {
+		t.Skip("patch not available")
+	}
+
+	before := "host: $HOST\nport: $PORT\nextra: unchanged\ntail: unchanged too\n"
+	after := "host: db01\nport: $PORT\nextra: unchanged\ntail: unchanged too\nappended: yes\n"
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "config.yml")
+	if err := os.WriteFile(target, []byte(before), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	patch := unifiedDiff("config.yml", before, after)
+	if patch == "" {
+		t.Fatal("expected a non-empty patch")
+	}
+	patchFile := filepath.Join(dir, "change.patch")
+	if err := os.WriteFile(patchFile, []byte(patch), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("patch", "-p1", "-d", dir, "-i", patchFile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("patch failed: %v\n%s\npatch contents:\n%s", err, out, patch)
+	}
+
+	got, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != after {
+		t.Fatalf("got %q, want %q", got, after)
+	}
+}
+
+func TestUnifiedDiffEmitsGitStyleHeadersWithGivenPath(t *testing.T) {
+	patch := unifiedDiff("conf/app.env", "a\nb\n", "a\nc\n")
+	wantPrefix := "--- a/conf/app.env\n+++ b/conf/app.env\n"
+	if len(patch) < len(wantPrefix) || patch[:len(wantPrefix)] != wantPrefix {
+		t.Fatalf("got %q, want prefix %q", patch, wantPrefix)
+	}
+}
+
+func TestUnifiedDiffReturnsEmptyStringWhenUnchanged(t *testing.T) {
+	if got := unifiedDiff("x", "same\n", "same\n"); got != "" {
+		t.Fatalf("got %q, want empty diff", got)
+	}
+}
+
+func TestGetOutputAsPatchWritesDiffInsteadOfRenderedOutput(t *testing.T) {
+	t.Setenv("ASPATCH_HOST", "db01")
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("host: $ASPATCH_HOST\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetAsPatch(true)
+
+	stdout := captureStdout(t, func() { GetOutput(cfg) })
+
+	wantPrefix := "--- a/" + tmpl + "\n+++ b/" + tmpl + "\n"
+	if len(stdout) < len(wantPrefix) || stdout[:len(wantPrefix)] != wantPrefix {
+		t.Fatalf("got %q, want prefix %q", stdout, wantPrefix)
+	}
+
+	original, err := os.ReadFile(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(original) != "host: $ASPATCH_HOST\n" {
+		t.Fatalf("--as-patch must not modify the template, got %q", original)
+	}
+}
@@ -0,0 +1,22 @@
+package parry
+
+import "testing"
+
+func TestValidateRejectsEditInPlaceWithNoFiles(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetEditInPlace(true)
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error for -i with no files (stdin input)")
+	}
+}
+
+func TestValidateAllowsEditInPlaceWithAFile(t *testing.T) {
+	path := writeParamsFile(t, "tmpl.txt", "plain text")
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.SetEditInPlace(true)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
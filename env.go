@@ -0,0 +1,216 @@
+package parry
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dlclark/regexp2"
+)
+
+// envFileParserPattern matches one `NAME=value` assignment line (optionally
+// prefixed with `export `) within an env file.
+const envFileParserPattern = `^(export )?(?<name>[A-Za-z_][A-Za-z0-9_]*)=(?<value>.*)$`
+
+// envFileParserRegexSingleLine matches one assignment line on its own,
+// used both per-record under --null-data and per-line otherwise -- env
+// files are always parsed one logical line at a time, so there's no need
+// for a Multiline variant scanning the whole file in one pass. It's
+// compiled with Singleline so `.` also matches `\n`: under --null-data a
+// record can be the entire NUL-free file, and its value is free to span
+// several physical lines (that's the point of NUL-delimited records, e.g.
+// find -print0 output captured whole into one value).
+var envFileParserRegexSingleLine = regexp2.MustCompile(envFileParserPattern, regexp2.Singleline)
+
+// isCommentOrBlankEnvLine reports whether line should be skipped entirely
+// when parsing an env file: empty, or starting with `#` once leading
+// whitespace is trimmed, following standard dotenv conventions.
+func isCommentOrBlankEnvLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// unescapeEnvValue reverses writeEnvFile's escapeEnvValue: `\\` becomes a
+// literal backslash, `\n` becomes a newline, and `\#` becomes a literal
+// `#` (which stripInlineComment never saw as a comment marker in the
+// first place, since it's preceded by `\` rather than whitespace). Any
+// other backslash is left as-is, so hand-written values like a Windows
+// path don't need escaping to survive parsing.
+func unescapeEnvValue(value string) string {
+	runes := []rune(value)
+	var b []rune
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			switch runes[i+1] {
+			case '\\':
+				b = append(b, '\\')
+				i++
+				continue
+			case 'n':
+				b = append(b, '\n')
+				i++
+				continue
+			case '#':
+				b = append(b, '#')
+				i++
+				continue
+			}
+		}
+		b = append(b, runes[i])
+	}
+	return string(b)
+}
+
+// stripInlineComment trims an env-file value's trailing ` # comment`,
+// honoring quoting via tokenizeByQuotes: a `#` inside single or double
+// quotes is part of the value, not a comment marker, and a `#` not
+// preceded by whitespace (or at the very start) doesn't count either.
+func stripInlineComment(value string) (string, error) {
+	runes := []rune(value)
+	segments, err := tokenizeByQuotes(runes)
+	if err != nil {
+		return "", err
+	}
+	for _, seg := range segments {
+		if seg.Type != unquoted {
+			continue
+		}
+		for i := seg.Start; i < seg.End; i++ {
+			if runes[i] == '#' && (i == 0 || runes[i-1] == ' ' || runes[i-1] == '\t') {
+				return strings.TrimRight(string(runes[:i]), " \t"), nil
+			}
+		}
+	}
+	return value, nil
+}
+
+// parseEnvFile parses `NAME=value` assignments out of path without touching
+// the process environment. Blank lines and `#` comment lines are skipped;
+// a panic only happens when a non-blank, non-comment line fails to parse
+// as an assignment. An unquoted ` #` within a value starts an inline
+// comment that's trimmed before the value is stored (see
+// stripInlineComment); this only applies to env-file parsing, not `-e`
+// overrides, which take their value verbatim. If the file starts with
+// writeEnvMarker -- meaning it's a --write-env snapshot, not something
+// hand-authored -- every value is also run through unescapeEnvValue,
+// reversing the backslash escaping writeEnvFile applies on write so the
+// snapshot round-trips exactly; an ordinary env file never has this
+// escaping applied; so a literal `\n` in a hand-written value (e.g. a
+// Windows path) survives unchanged. When nullData is set, records are
+// split on NUL bytes instead of newlines.
+//
+// A value may reference an earlier name defined in the same file, e.g.
+// `DIR=/opt/app` followed by `LOG=${DIR}/log`, since each value is expanded
+// against the names parsed so far (not the process environment or any
+// later name) before being stored. A forward reference to a name not yet
+// defined resolves to empty, the same as any other unset variable --
+// unless cfg.strict is set, in which case it's reported the same way any
+// other undefined variable is under --strict. cfg may be nil.
+func parseEnvFile(path string, nullData bool, cfg *Config) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, wrapFileError(path, err)
+	}
+
+	sep := "\n"
+	if nullData {
+		sep = "\x00"
+	}
+
+	content := string(data)
+	firstLine, _, _ := strings.Cut(content, "\n")
+	fromWriteEnv := strings.TrimSuffix(firstLine, "\r") == writeEnvMarker
+
+	values := make(map[string]string)
+	lineCfg := &Config{lookuper: LookupFunc(func(name string) (string, bool) {
+		v, ok := values[name]
+		return v, ok
+	})}
+	if cfg != nil {
+		lineCfg.strict = cfg.strict
+	}
+
+	for _, line := range strings.Split(content, sep) {
+		if !nullData {
+			// A CRLF-terminated file splits on "\n" alone into lines that
+			// still carry a trailing "\r" -- trim it so it doesn't end up
+			// baked into the parsed value (envFileParserPattern's trailing
+			// ".*" would otherwise happily capture it).
+			line = strings.TrimSuffix(line, "\r")
+		}
+		if isCommentOrBlankEnvLine(line) {
+			continue
+		}
+		m, _ := envFileParserRegexSingleLine.FindStringMatch(line)
+		if m == nil {
+			return nil, &ParseError{Fragment: line, Position: -1, Message: "invalid env assignment syntax", Class: ClassEnvFile}
+		}
+		value, err := stripInlineComment(m.GroupByName("value").String())
+		if err != nil {
+			return nil, err
+		}
+		if fromWriteEnv {
+			value = unescapeEnvValue(value)
+		}
+		expanded, err := parseEmbeddedParams(value, lineCfg)
+		if err != nil {
+			return nil, err
+		}
+		values[m.GroupByName("name").String()] = expanded
+	}
+	return values, nil
+}
+
+// setEnv loads `NAME=value` assignments from path into the process
+// environment, one call to os.Setenv per parsed assignment.
+func setEnv(path string, nullData bool, cfg *Config) error {
+	values, err := parseEnvFile(path, nullData, cfg)
+	if err != nil {
+		return err
+	}
+	for name, value := range values {
+		os.Setenv(name, value)
+	}
+	return nil
+}
+
+// parseEnvOverride splits a single `-e NAME=value` style override into its
+// name and value, without touching the process environment.
+func parseEnvOverride(assignment string) (name, value string) {
+	for i, c := range assignment {
+		if c == '=' {
+			return assignment[:i], assignment[i+1:]
+		}
+	}
+	return assignment, ""
+}
+
+// setEnvOverride applies a single `-e NAME=value` style override.
+func setEnvOverride(assignment string) {
+	name, value := parseEnvOverride(assignment)
+	os.Setenv(name, value)
+}
+
+// parseEnvFileIfSpec splits a --envfile-if argument of the form
+// "NAME=value:path" into its condition ("NAME=value") and the env file path
+// to load when that condition holds.
+func parseEnvFileIfSpec(spec string) (cond, path string, err error) {
+	cond, path, found := strings.Cut(spec, ":")
+	if !found {
+		return "", "", fmt.Errorf("--envfile-if expects NAME=value:path, got %q", spec)
+	}
+	if !strings.Contains(cond, "=") {
+		return "", "", fmt.Errorf("--envfile-if condition must be NAME=value, got %q", cond)
+	}
+	return cond, path, nil
+}
+
+// evalEnvFileIfCond reports whether cond (a "NAME=value" string, as parsed
+// by parseEnvFileIfSpec) holds against getEnv, the same source GetOutput's
+// --envfile/--envtoml loading consults (the restricted map under --no-env,
+// or the process environment otherwise).
+func evalEnvFileIfCond(cond string, getEnv func(string) (string, bool)) bool {
+	name, want, _ := strings.Cut(cond, "=")
+	got, ok := getEnv(name)
+	return ok && got == want
+}
@@ -0,0 +1,172 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetEnvNullData(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	content := "FOO=bar\x00BAZ=qux with spaces\x00"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("FOO")
+	defer os.Unsetenv("BAZ")
+
+	if err := setEnv(path, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("FOO"); got != "bar" {
+		t.Fatalf("FOO: got %q, want %q", got, "bar")
+	}
+	if got := os.Getenv("BAZ"); got != "qux with spaces" {
+		t.Fatalf("BAZ: got %q, want %q", got, "qux with spaces")
+	}
+}
+
+func TestSetEnvNullDataWholeFileRecordSpansMultipleLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	content := "BODY=line one\nline two\nline three"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv("BODY")
+
+	if err := setEnv(path, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("BODY"); got != "line one\nline two\nline three" {
+		t.Fatalf("BODY: got %q", got)
+	}
+}
+
+func TestParseEnvFileLeavesBackslashesAloneInHandAuthoredFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	content := `PATHVAR=C:\new\file` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := parseEnvFile(path, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["PATHVAR"] != `C:\new\file` {
+		t.Fatalf("got %q, want %q -- a hand-authored file isn't a --write-env snapshot, so \\n shouldn't be unescaped", values["PATHVAR"], `C:\new\file`)
+	}
+}
+
+func TestParseEnvFileSkipsCommentsAndBlankLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	content := "# a comment\n\nFOO=bar\n   \n  # indented comment\nBAZ=qux\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := parseEnvFile(path, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"FOO": "bar", "BAZ": "qux"}
+	if len(values) != len(want) || values["FOO"] != want["FOO"] || values["BAZ"] != want["BAZ"] {
+		t.Fatalf("got %v, want %v", values, want)
+	}
+}
+
+func TestParseEnvFileEmptyFileYieldsNoValuesWithoutError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	if err := os.WriteFile(path, []byte("# only a comment\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := parseEnvFile(path, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 0 {
+		t.Fatalf("got %v, want no values", values)
+	}
+}
+
+func TestParseEnvFileStripsInlineComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	content := "PORT=8080 # default port\nMSG='hello # not a comment'\nOTHER=\"x # still not\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := parseEnvFile(path, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"PORT":  "8080",
+		"MSG":   "'hello # not a comment'",
+		"OTHER": `"x # still not"`,
+	}
+	for name, w := range want {
+		if values[name] != w {
+			t.Fatalf("%s: got %q, want %q", name, values[name], w)
+		}
+	}
+}
+
+func TestParseEnvFileResolvesReferencesToEarlierNames(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	content := "DIR=/opt/app\nLOG=${DIR}/log\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := parseEnvFile(path, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["LOG"] != "/opt/app/log" {
+		t.Fatalf("LOG: got %q, want %q", values["LOG"], "/opt/app/log")
+	}
+}
+
+func TestParseEnvFileForwardReferenceResolvesEmptyUnlessStrict(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	content := "LOG=${DIR}/log\nDIR=/opt/app\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := parseEnvFile(path, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["LOG"] != "/log" {
+		t.Fatalf("LOG: got %q, want %q", values["LOG"], "/log")
+	}
+
+	cfg := NewConfig()
+	cfg.SetStrict(true)
+	if _, err := parseEnvFile(path, false, cfg); err == nil {
+		t.Fatal("expected a forward reference to DIR to fail under --strict")
+	}
+}
+
+func TestParseEnvFileInvalidLineStillErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "env")
+	if err := os.WriteFile(path, []byte("# comment\nnot an assignment\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parseEnvFile(path, false, nil); err == nil {
+		t.Fatal("expected an error for a non-blank, non-comment line that isn't an assignment")
+	}
+}
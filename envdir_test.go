@@ -0,0 +1,48 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetOutputEnvDirLayersFilesInLexicalOrder(t *testing.T) {
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"00-base.env": "ENVDIR_HOST=db00\nENVDIR_PORT=5432\n",
+		"10-prod.env": "ENVDIR_HOST=db01\n",
+		"ignored.txt": "ENVDIR_HOST=should-not-load\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(confDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.Unsetenv("ENVDIR_HOST")
+	defer os.Unsetenv("ENVDIR_PORT")
+
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("$ENVDIR_HOST:$ENVDIR_PORT"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetEnvDir(confDir)
+
+	if got := captureStdout(t, func() { GetOutput(cfg) }); got != "db01:5432" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestConfigValidateRejectsMissingEnvDir(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetEnvDir("/nonexistent-env-dir")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a missing env-dir")
+	}
+}
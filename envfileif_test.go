@@ -0,0 +1,67 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetOutputEnvFileIfLoadsFileWhenConditionHolds(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "prod.env")
+	if err := os.WriteFile(envFile, []byte("ENVFILEIF_HOST=prod-db\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("ENVFILEIF_FLAG", "1")
+	defer os.Unsetenv("ENVFILEIF_FLAG")
+	defer os.Unsetenv("ENVFILEIF_HOST")
+
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("$ENVFILEIF_HOST"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetEnvFileIf("ENVFILEIF_FLAG=1:" + envFile)
+
+	if got := captureStdout(t, func() { GetOutput(cfg) }); got != "prod-db" {
+		t.Fatalf("got %q, want %q", got, "prod-db")
+	}
+}
+
+func TestGetOutputEnvFileIfSkipsFileWhenConditionFails(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "prod.env")
+	if err := os.WriteFile(envFile, []byte("ENVFILEIF_HOST2=prod-db\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("ENVFILEIF_FLAG2", "0")
+	defer os.Unsetenv("ENVFILEIF_FLAG2")
+	os.Unsetenv("ENVFILEIF_HOST2")
+
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("$ENVFILEIF_HOST2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetEnvFileIf("ENVFILEIF_FLAG2=1:" + envFile)
+
+	if got := captureStdout(t, func() { GetOutput(cfg) }); got != "" {
+		t.Fatalf("got %q, want empty", got)
+	}
+}
+
+func TestParseEnvFileIfSpecRejectsMissingColon(t *testing.T) {
+	if _, _, err := parseEnvFileIfSpec("FLAG=1"); err == nil {
+		t.Fatal("expected an error for a spec with no path")
+	}
+}
+
+func TestParseEnvFileIfSpecRejectsMissingEquals(t *testing.T) {
+	if _, _, err := parseEnvFileIfSpec("FLAG:prod.env"); err == nil {
+		t.Fatal("expected an error for a condition with no '='")
+	}
+}
@@ -0,0 +1,26 @@
+package parry
+
+import "strings"
+
+// expandEnvFormat expands payload as an env file rather than free-form
+// text: only the value half of each `NAME=value`/`export NAME=value`
+// assignment line is expanded. Every other line -- comments, blank lines,
+// anything that doesn't parse as an assignment -- passes through
+// completely verbatim, even if it contains text that looks like a param,
+// since a `# default is $HOME` comment shouldn't get rewritten.
+func expandEnvFormat(payload []rune, cfg *Config) (string, error) {
+	lines := strings.Split(string(payload), "\n")
+	for i, line := range lines {
+		m, _ := envFileParserRegexSingleLine.FindStringMatch(line)
+		if m == nil {
+			continue
+		}
+		value := m.GroupByName("value").String()
+		expanded, err := parseEmbeddedParams(value, cfg)
+		if err != nil {
+			return "", err
+		}
+		lines[i] = line[:len(line)-len(value)] + expanded
+	}
+	return strings.Join(lines, "\n"), nil
+}
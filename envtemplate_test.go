@@ -0,0 +1,54 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandEnvFormatKeepsCommentsAndBlankLinesVerbatim(t *testing.T) {
+	t.Setenv("ENVTMPL_HOST", "db01")
+
+	tmpl := "# default host is $HOME\n\nHOST=$ENVTMPL_HOST\n\n# trailing comment\n"
+	got, err := expandEnvFormat([]rune(tmpl), NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# default host is $HOME\n\nHOST=db01\n\n# trailing comment\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestExpandEnvFormatExpandsExportAssignments(t *testing.T) {
+	t.Setenv("ENVTMPL_PORT", "5432")
+
+	got, err := expandEnvFormat([]rune("export PORT=$ENVTMPL_PORT"), NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "export PORT=5432" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestGetOutputKeepEmptyEnvLinesRoundTripsEnvFile(t *testing.T) {
+	t.Setenv("ENVTMPL_GO_HOST", "db01")
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "app.env")
+	content := "# comment referencing $HOME is left alone\nHOST=$ENVTMPL_GO_HOST\n\nPORT=5432\n"
+	if err := os.WriteFile(tmpl, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetKeepEmptyEnvLines(true)
+
+	stdout := captureStdout(t, func() { GetOutput(cfg) })
+	want := "# comment referencing $HOME is left alone\nHOST=db01\n\nPORT=5432\n"
+	if stdout != want {
+		t.Fatalf("got %q, want %q", stdout, want)
+	}
+}
@@ -0,0 +1,70 @@
+package parry
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// parseEnvTOMLFile parses path as a TOML document and returns its scalars
+// as `NAME=value` style assignments, without touching the process
+// environment. When table is non-empty, only that top-level table's
+// scalars are returned (with table itself stripped from the name); an
+// array or a nested table anywhere in the selected scope is rejected,
+// since there's no sensible string to assign it to.
+func parseEnvTOMLFile(path, table string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, wrapFileError(path, err)
+	}
+
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, &ParseError{Fragment: path, Position: -1, Message: "invalid TOML: " + err.Error(), Class: ClassEnvFile}
+	}
+
+	if table != "" {
+		sub, ok := doc[table].(map[string]interface{})
+		if !ok {
+			return nil, &ParseError{Fragment: table, Position: -1, Message: fmt.Sprintf("no such TOML table: %s", table), Class: ClassEnvFile}
+		}
+		doc = sub
+	}
+
+	values := make(map[string]string, len(doc))
+	for name, v := range doc {
+		value, err := tomlScalarString(v)
+		if err != nil {
+			return nil, &ParseError{Fragment: name, Position: -1, Message: err.Error(), Class: ClassEnvFile}
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// tomlScalarString stringifies a decoded TOML scalar. Arrays and nested
+// tables are rejected: there's no single string they could reasonably
+// become without picking an arbitrary encoding, so --envtoml requires
+// --toml-table to descend into them instead.
+func tomlScalarString(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return t, nil
+	case bool:
+		return fmt.Sprintf("%t", t), nil
+	case int64:
+		return fmt.Sprintf("%d", t), nil
+	case float64:
+		return fmt.Sprintf("%v", t), nil
+	case time.Time:
+		return t.Format(time.RFC3339), nil
+	case map[string]interface{}:
+		return "", fmt.Errorf("value is a table; select it with --toml-table")
+	case []interface{}:
+		return "", fmt.Errorf("value is an array; --envtoml only loads scalars")
+	default:
+		return "", fmt.Errorf("unsupported TOML value of type %T", t)
+	}
+}
@@ -0,0 +1,87 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTOML(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseEnvTOMLFileFlatTable(t *testing.T) {
+	path := writeTOML(t, `
+host = "db01"
+port = 5432
+debug = true
+`)
+
+	values, err := parseEnvTOMLFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"host": "db01", "port": "5432", "debug": "true"}
+	for name, wantValue := range want {
+		if got := values[name]; got != wantValue {
+			t.Errorf("%s: got %q, want %q", name, got, wantValue)
+		}
+	}
+}
+
+func TestParseEnvTOMLFileSelectsSubTable(t *testing.T) {
+	path := writeTOML(t, `
+title = "ignored at top level"
+
+[env]
+host = "db01"
+port = 5432
+`)
+
+	values, err := parseEnvTOMLFile(path, "env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 {
+		t.Fatalf("got %d values, want 2: %v", len(values), values)
+	}
+	if values["host"] != "db01" || values["port"] != "5432" {
+		t.Fatalf("got %v", values)
+	}
+	if _, ok := values["title"]; ok {
+		t.Fatal("title from outside the selected table leaked in")
+	}
+}
+
+func TestParseEnvTOMLFileRejectsArrayWithoutSubTable(t *testing.T) {
+	path := writeTOML(t, `hosts = ["a", "b"]`)
+
+	if _, err := parseEnvTOMLFile(path, ""); err == nil {
+		t.Fatal("expected an error for an array value")
+	}
+}
+
+func TestParseEnvTOMLFileRejectsNestedTableWithoutSubTable(t *testing.T) {
+	path := writeTOML(t, `
+[env]
+host = "db01"
+`)
+
+	if _, err := parseEnvTOMLFile(path, ""); err == nil {
+		t.Fatal("expected an error for a nested table")
+	}
+}
+
+func TestParseEnvTOMLFileUnknownTableErrors(t *testing.T) {
+	path := writeTOML(t, `host = "db01"`)
+
+	if _, err := parseEnvTOMLFile(path, "missing"); err == nil {
+		t.Fatal("expected an error for an unknown table")
+	}
+}
@@ -0,0 +1,30 @@
+package parry
+
+import "bytes"
+
+// utf8BOM is the three-byte UTF-8 byte order mark.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// stripUTF8BOM removes a leading UTF-8 byte order mark from data, if
+// present, leaving data untouched otherwise.
+func stripUTF8BOM(data []byte) []byte {
+	if bytes.HasPrefix(data, utf8BOM) {
+		return data[len(utf8BOM):]
+	}
+	return data
+}
+
+// crlfToLF rewrites every "\r\n" in data to "\n", dos2unix-style.
+func crlfToLF(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+}
+
+// convertEOL implements --convert-eol-only: CRLF-to-LF conversion, plus
+// BOM removal when stripBOM is set, on raw bytes with no tokenization or
+// param expansion involved at all.
+func convertEOL(data []byte, stripBOM bool) []byte {
+	if stripBOM {
+		data = stripUTF8BOM(data)
+	}
+	return crlfToLF(data)
+}
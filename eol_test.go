@@ -0,0 +1,47 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertEOLConvertsCRLFToLF(t *testing.T) {
+	got := string(convertEOL([]byte("a\r\nb\r\nc"), false))
+	want := "a\nb\nc"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestConvertEOLStripsBOMWhenRequested(t *testing.T) {
+	data := append([]byte{0xEF, 0xBB, 0xBF}, []byte("a\r\nb")...)
+	got := string(convertEOL(data, true))
+	want := "a\nb"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestGetOutputConvertEOLOnlyRewritesFileInPlaceWithNoParamsPresent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(path, []byte("one\r\ntwo\r\nthree"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.SetConvertEOLOnly(true)
+	cfg.SetEditInPlace(true)
+	GetOutput(cfg)
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "one\ntwo\nthree"
+	if string(got) != want {
+		t.Fatalf("got %q, want %q", string(got), want)
+	}
+}
@@ -0,0 +1,51 @@
+package parry
+
+import "testing"
+
+func TestRequiredVariableFailureIsClassified(t *testing.T) {
+	cfg := NewConfig()
+	_, err := mustExpandErr(t, "v=${MISSING:?must be set}", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Class != ClassRequiredVariable {
+		t.Fatalf("got class %q, want %q", pe.Class, ClassRequiredVariable)
+	}
+}
+
+func TestUnmatchedQuoteFailureIsClassified(t *testing.T) {
+	_, err := tokenizeByQuotes([]rune(`a="unterminated`))
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Class != ClassParse {
+		t.Fatalf("got class %q, want %q", pe.Class, ClassParse)
+	}
+}
+
+func TestMissingFileFailureIsClassified(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFile("/no/such/file-errorclass-test.tmpl")
+	err := cfg.Validate()
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Class != ClassFileNotFound {
+		t.Fatalf("got class %q, want %q", pe.Class, ClassFileNotFound)
+	}
+}
+
+func TestMalformedEnvFileFailureIsClassified(t *testing.T) {
+	path := writeParamsFile(t, "errorclass.env", "not a valid line\n")
+	_, err := parseEnvFile(path, false, nil)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Class != ClassEnvFile {
+		t.Fatalf("got class %q, want %q", pe.Class, ClassEnvFile)
+	}
+}
@@ -0,0 +1,72 @@
+package parry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrorClass categorizes a ParseError by the kind of failure it represents,
+// so a caller like cmd/parry can map a failure to a distinct process exit
+// code instead of a single catch-all. An empty ErrorClass means the error
+// doesn't fall cleanly into one of the categories below.
+type ErrorClass string
+
+const (
+	// ClassFileNotFound covers a template, env-file, kvjson, or params-file
+	// path that doesn't exist on disk.
+	ClassFileNotFound ErrorClass = "file-not-found"
+	// ClassParse covers malformed template text: an unmatched quote, a
+	// disallowed construct under --interpret, an oversized value, and the
+	// like.
+	ClassParse ErrorClass = "parse"
+	// ClassRequiredVariable covers a `?`/`:?` operator, --strict, or
+	// --params-file `required` failure -- a variable that had to be set and
+	// wasn't.
+	ClassRequiredVariable ErrorClass = "required-variable"
+	// ClassEnvFile covers malformed content inside an otherwise-present
+	// --envfile/--envfile-toml/--kvjson file.
+	ClassEnvFile ErrorClass = "env-file"
+)
+
+// ParseError reports a failure encountered while parsing or resolving
+// template text, carrying enough context for a caller to point at the
+// offending fragment instead of just a bare message.
+type ParseError struct {
+	Fragment string // the offending text, e.g. a raw param or a malformed env line
+	Position int    // rune offset within the original input, or -1 if not applicable
+	Message  string
+
+	// Line and Column are a 1-based, rune-counted location for Position,
+	// filled in by callers that have the original payload on hand (via
+	// newlineOffsets/lineAndColumn). Line 0 means no location is available,
+	// even if Position is -- e.g. because the error was raised somewhere
+	// that only has an offset into a payload it was never handed directly.
+	Line   int
+	Column int
+
+	// Class categorizes the failure for exit-code mapping; see ErrorClass.
+	// It may be empty.
+	Class ErrorClass
+}
+
+func (e *ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s: %q (at line %d, column %d)", e.Message, e.Fragment, e.Line, e.Column)
+	}
+	if e.Position >= 0 {
+		return fmt.Sprintf("%s: %q (at offset %d)", e.Message, e.Fragment, e.Position)
+	}
+	return fmt.Sprintf("%s: %q", e.Message, e.Fragment)
+}
+
+// wrapFileError marks err as ClassFileNotFound when it reflects a missing
+// path, so cmd/parry can map it to its own exit code instead of the
+// catch-all. Other errors (e.g. a permission failure) are returned
+// unchanged.
+func wrapFileError(path string, err error) error {
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return &ParseError{Fragment: path, Position: -1, Message: "file does not exist: " + path, Class: ClassFileNotFound}
+}
@@ -0,0 +1,54 @@
+package parry
+
+import "testing"
+
+func TestEscapedClosingBraceInOperandIsLiteral(t *testing.T) {
+	params, err := findParams([]rune(`${FOO:-\}bar}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(params) != 1 {
+		t.Fatalf("got %d params, want 1: %v", len(params), params)
+	}
+	p := params[0]
+	if p.Name != "FOO" {
+		t.Fatalf("got name %q, want %q", p.Name, "FOO")
+	}
+	if p.Operator != ":-" {
+		t.Fatalf("got operator %q, want %q", p.Operator, ":-")
+	}
+	if p.Operand != "}bar" {
+		t.Fatalf("got operand %q, want %q", p.Operand, "}bar")
+	}
+	if p.Raw != `${FOO:-\}bar}` {
+		t.Fatalf("got raw %q, want the unescaped backslash preserved", p.Raw)
+	}
+}
+
+func TestEscapedOpenAndClosingBracesInOperand(t *testing.T) {
+	params, err := findParams([]rune(`${FOO:-a\{b\}c}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(params) != 1 {
+		t.Fatalf("got %d params, want 1: %v", len(params), params)
+	}
+	if got := params[0].Operand; got != "a{b}c" {
+		t.Fatalf("got operand %q, want %q", got, "a{b}c")
+	}
+}
+
+func TestEscapedBraceExpandsWithLiteralBraceInResult(t *testing.T) {
+	got := mustExpand(t, `${UNSET_ESCAPED_BRACE:-\}tail}`, NewConfig())
+	if got != "}tail" {
+		t.Fatalf("got %q, want %q", got, "}tail")
+	}
+}
+
+func TestNestedBracedDefaultStillWorksAlongsideEscapedBraceSupport(t *testing.T) {
+	t.Setenv("ESCAPED_BRACE_INNER", "inner")
+	got := mustExpand(t, "${ESCAPED_BRACE_OUTER:-${ESCAPED_BRACE_INNER}}", NewConfig())
+	if got != "inner" {
+		t.Fatalf("got %q, want %q", got, "inner")
+	}
+}
@@ -0,0 +1,33 @@
+package parry
+
+import "encoding/json"
+
+// escapeWithMode selects which escape-sequence grammar ${VAR@E} interprets,
+// as chosen by --escape-mode: "c" (the default, escapeHandler's backslash
+// n/t/r/\\/"/' handling), "json" (the full JSON string escape grammar,
+// including \u-style unicode escapes), or "none" (value passes through
+// untouched). An empty mode behaves like "c".
+func escapeWithMode(value, mode string) (string, error) {
+	switch mode {
+	case "", "c":
+		return escapeHandler(value), nil
+	case "none":
+		return value, nil
+	case "json":
+		return escapeJSONString(value)
+	default:
+		return "", &ParseError{Message: "unknown escape-mode: " + mode}
+	}
+}
+
+// escapeJSONString interprets value as the contents of a JSON string
+// literal (without its surrounding quotes) and returns the decoded text.
+// A value containing an unescaped '"' isn't valid JSON under this
+// treatment and returns an error, same as any other malformed escape.
+func escapeJSONString(value string) (string, error) {
+	var out string
+	if err := json.Unmarshal([]byte(`"`+value+`"`), &out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
@@ -0,0 +1,38 @@
+package parry
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindVariablesReturnsDistinctNamesInFirstAppearanceOrder(t *testing.T) {
+	input := "${Greeting}, $NAME! ${NAME} again, and $CITY:-default} but really ${CITY}."
+	got := FindVariables(input)
+	want := []string{"Greeting", "NAME", "CITY"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindVariablesIgnoresNonVariableText(t *testing.T) {
+	got := FindVariables("no variables here, just $ and ${} and text")
+	if len(got) != 0 {
+		t.Fatalf("got %v, want empty", got)
+	}
+}
+
+func BenchmarkFindVariables(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 2000; i++ {
+		sb.WriteString("Lorem ipsum dolor sit amet ${VAR")
+		sb.WriteString(string(rune('A' + i%26)))
+		sb.WriteString("} consectetur $OTHER adipiscing elit. ")
+	}
+	input := sb.String()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		FindVariables(input)
+	}
+}
@@ -0,0 +1,53 @@
+package parry
+
+import (
+	"os"
+	"strings"
+)
+
+// highlightColorStart/highlightColorReset wrap a param occurrence in cyan
+// for --highlight's terminal output.
+const (
+	highlightColorStart = "\x1b[36m"
+	highlightColorReset = "\x1b[0m"
+)
+
+// shouldColorizeHighlight reports whether --highlight should wrap param
+// occurrences in ANSI color codes: only when stdout is a terminal, unless
+// --color=always forces it regardless of where stdout points.
+func shouldColorizeHighlight(cfg *Config) bool {
+	if cfg != nil && cfg.colorAlways {
+		return true
+	}
+	return stdoutIsTTY()
+}
+
+// stdoutIsTTY reports whether stdout is attached to a terminal rather than
+// a pipe or a redirected file.
+func stdoutIsTTY() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// highlightParams renders payload unexpanded, wrapping each param in
+// params (by its Position, in the order findParams found them) in ANSI
+// color codes when colorize is set, for --highlight.
+func highlightParams(payload []rune, params []Param, colorize bool) string {
+	if !colorize || len(params) == 0 {
+		return string(payload)
+	}
+	var b strings.Builder
+	last := 0
+	for _, p := range params {
+		b.WriteString(string(payload[last:p.Position[0]]))
+		b.WriteString(highlightColorStart)
+		b.WriteString(string(payload[p.Position[0]:p.Position[1]]))
+		b.WriteString(highlightColorReset)
+		last = p.Position[1]
+	}
+	b.WriteString(string(payload[last:]))
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package parry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlightParamsWrapsEachOccurrenceWhenColorized(t *testing.T) {
+	payload := []rune("a=$FOO b=${BAR:-baz}")
+	params, err := findParams(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := highlightParams(payload, params, true)
+	want := "a=" + highlightColorStart + "$FOO" + highlightColorReset +
+		" b=" + highlightColorStart + "${BAR:-baz}" + highlightColorReset
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestHighlightParamsLeavesPlainWhenNotColorized(t *testing.T) {
+	payload := []rune("a=$FOO")
+	params, err := findParams(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := highlightParams(payload, params, false)
+	if got != string(payload) {
+		t.Fatalf("got %q, want %q", got, string(payload))
+	}
+}
+
+func TestGetOutputHighlightWithColorAlwaysSurroundsParams(t *testing.T) {
+	t.Setenv("HIGHLIGHT_VAR", "value")
+	got := runGetOutput(t, "a=$HIGHLIGHT_VAR b=literal", func(c *Config) {
+		c.SetHighlight(true)
+		c.SetColorAlways(true)
+	})
+	want := "a=" + highlightColorStart + "$HIGHLIGHT_VAR" + highlightColorReset + " b=literal\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if strings.Contains(got, "value") {
+		t.Fatalf("expected template to stay unexpanded, got %q", got)
+	}
+}
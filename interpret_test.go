@@ -0,0 +1,59 @@
+package parry
+
+import "testing"
+
+func TestInterpretPosixAllowsStandardOperators(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetInterpret("posix")
+	got := mustExpand(t, "${MISSING_POSIX:-fallback}", cfg)
+	if got != "fallback" {
+		t.Fatalf("got %q, want %q", got, "fallback")
+	}
+}
+
+func TestInterpretPosixRejectsBashExtensions(t *testing.T) {
+	t.Setenv("INTERPRET_SUBST", "hello")
+	cfg := NewConfig()
+	cfg.SetInterpret("posix")
+	_, err := parseEmbeddedParams("${INTERPRET_SUBST:0:2}", cfg)
+	if err == nil {
+		t.Fatal("expected an error for a bash substring operator under --interpret=posix")
+	}
+}
+
+func TestInterpretPosixRejectsIndirection(t *testing.T) {
+	t.Setenv("INTERPRET_PTR", "INTERPRET_TARGET")
+	t.Setenv("INTERPRET_TARGET", "value")
+	cfg := NewConfig()
+	cfg.SetInterpret("posix")
+	_, err := parseEmbeddedParams("${!INTERPRET_PTR}", cfg)
+	if err == nil {
+		t.Fatal("expected an error for indirection under --interpret=posix")
+	}
+}
+
+func TestInterpretPosixRejectsPrefixMatch(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetInterpret("posix")
+	_, err := parseEmbeddedParams("${@prefix:APP_}", cfg)
+	if err == nil {
+		t.Fatal("expected an error for ${@prefix:...} under --interpret=posix")
+	}
+}
+
+func TestInterpretBashIsDefaultAndUnrestricted(t *testing.T) {
+	t.Setenv("INTERPRET_BASH", "hello")
+	cfg := NewConfig()
+	got := mustExpand(t, "${INTERPRET_BASH:0:2}", cfg)
+	if got != "he" {
+		t.Fatalf("got %q, want %q", got, "he")
+	}
+}
+
+func TestConfigValidateRejectsUnknownInterpretDialect(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetInterpret("perl")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown --interpret dialect")
+	}
+}
@@ -0,0 +1,62 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKVJSONSuppliesValueWhenUnsetInEnv(t *testing.T) {
+	os.Unsetenv("KVJSON_UNSET")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "values.json")
+	if err := os.WriteFile(file, []byte(`{"KVJSON_UNSET": "from-json", "PORT": 8080}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.SetKVJSONFile(file)
+
+	got := mustExpand(t, "$KVJSON_UNSET:$PORT", cfg)
+	if got != "from-json:8080" {
+		t.Fatalf("got %q, want %q", got, "from-json:8080")
+	}
+}
+
+func TestKVJSONYieldsToEnvWhenBothSet(t *testing.T) {
+	os.Setenv("KVJSON_OVERRIDDEN", "from-env")
+	defer os.Unsetenv("KVJSON_OVERRIDDEN")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "values.json")
+	if err := os.WriteFile(file, []byte(`{"KVJSON_OVERRIDDEN": "from-json"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.SetKVJSONFile(file)
+
+	got := mustExpand(t, "$KVJSON_OVERRIDDEN", cfg)
+	if got != "from-env" {
+		t.Fatalf("got %q, want %q", got, "from-env")
+	}
+}
+
+func TestKVJSONMissingVariableStillResolvesEmpty(t *testing.T) {
+	os.Unsetenv("KVJSON_NOWHERE")
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "values.json")
+	if err := os.WriteFile(file, []byte(`{"OTHER": "x"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.SetKVJSONFile(file)
+
+	got := mustExpand(t, "$KVJSON_NOWHERE", cfg)
+	if got != "" {
+		t.Fatalf("got %q, want empty string", got)
+	}
+}
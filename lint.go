@@ -0,0 +1,71 @@
+package parry
+
+// LintIssue describes a problem Lint found in a template without
+// expanding it.
+type LintIssue struct {
+	Param    string // the full param text, e.g. `${FOO:-"bar'}`
+	Position int    // rune offset where Param starts
+	Message  string
+}
+
+// Lint scans input for params whose operand has unbalanced or mixed quote
+// characters -- e.g. `${FOO:-"bar'}` -- which can silently mis-resolve
+// through quoteHandler instead of failing until expansion time. It never
+// expands anything, so it's safe to run over a template with no
+// environment available at all.
+//
+// This is a heuristic quote-balance check, not a real quote parser: a
+// default like `${FOO:-it's fine}` has a single unbalanced apostrophe and
+// will also be flagged, even though it's perfectly valid. Treat a Lint
+// issue as something worth a human's attention, not a hard error.
+func Lint(input string) ([]LintIssue, error) {
+	payload := []rune(input)
+	params, err := findParams(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	for _, p := range params {
+		if !p.Braced || p.Operand == "" {
+			continue
+		}
+		if msg := mixedQuoteIssue(p.Operand); msg != "" {
+			issues = append(issues, LintIssue{Param: p.Raw, Position: p.Position[0], Message: msg})
+		}
+	}
+	return issues, nil
+}
+
+// mixedQuoteIssue reports why operand's quoting looks suspect, or ""
+// if it doesn't. It flags an odd count of either quote character (one
+// opened but never closed) as well as operands that use both quote
+// styles at all, which together catch a `"bar'`-style typo.
+func mixedQuoteIssue(operand string) string {
+	singles, doubles := 0, 0
+	for i := 0; i < len(operand); i++ {
+		c := operand[i]
+		if c == '\\' && i+1 < len(operand) {
+			i++
+			continue
+		}
+		switch c {
+		case '\'':
+			singles++
+		case '"':
+			doubles++
+		}
+	}
+
+	switch {
+	case singles%2 != 0 && doubles%2 != 0:
+		return "operand mixes unbalanced single and double quotes"
+	case singles%2 != 0:
+		return "operand has an unbalanced single quote"
+	case doubles%2 != 0:
+		return "operand has an unbalanced double quote"
+	case singles > 0 && doubles > 0:
+		return "operand mixes single and double quotes"
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package parry
+
+import "testing"
+
+func TestLintFlagsMixedUnbalancedQuoteOperand(t *testing.T) {
+	issues, err := Lint(`value=${FOO:-"bar'}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("got %d issues, want 1: %v", len(issues), issues)
+	}
+	if issues[0].Param != `${FOO:-"bar'}` {
+		t.Errorf("got param %q", issues[0].Param)
+	}
+	if issues[0].Position != 6 {
+		t.Errorf("got position %d, want 6", issues[0].Position)
+	}
+}
+
+func TestLintAllowsBalancedQuotesOfOneStyle(t *testing.T) {
+	issues, err := Lint(`value=${FOO:-"a quoted default"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("got %d issues, want 0: %v", len(issues), issues)
+	}
+}
+
+func TestLintIgnoresParamsWithNoOperand(t *testing.T) {
+	issues, err := Lint(`value=$FOO other=${BAR}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("got %d issues, want 0: %v", len(issues), issues)
+	}
+}
@@ -0,0 +1,272 @@
+package parry
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParamJson is the per-param record emitted by --list. Value is a pointer
+// so an unset variable serializes as JSON `null`, distinguishable from a
+// set-but-empty variable's `""`; Set reports the same distinction
+// explicitly for non-JSON consumers. Index/End are rune offsets into the
+// payload (matching Param.Position); ByteIndex/ByteEnd give the same span
+// in UTF-8 byte offsets, for editor integrations that index by byte.
+// Line/Column locate Index as a 1-based line and column, both counted in
+// runes, for templates too large to eyeball an absolute offset in.
+type ParamJson struct {
+	Param     string  `json:"param" yaml:"param"`
+	Index     int     `json:"index" yaml:"index"`
+	End       int     `json:"end" yaml:"end"`
+	ByteIndex int     `json:"byteIndex" yaml:"byteIndex"`
+	ByteEnd   int     `json:"byteEnd" yaml:"byteEnd"`
+	Line      int     `json:"line,omitempty" yaml:"line,omitempty"`
+	Column    int     `json:"column,omitempty" yaml:"column,omitempty"`
+	Value     *string `json:"value,omitempty" yaml:"value,omitempty"`
+	Set       bool    `json:"set,omitempty" yaml:"set,omitempty"`
+}
+
+// listParams renders params per config.format ("json" by default, or
+// "csv"/"tab"/"name-value"/"plain"/"yaml") to stdout. "plain" prints one
+// param per line as `index<TAB>param`, with no wrapping at all -- a
+// param-free file simply produces no output. payload is the original
+// template text the params were found in, used to translate each param's
+// rune offsets into byte offsets and line/column; it may be nil, in which
+// case ByteIndex/ByteEnd/Line/Column are all left at 0. When
+// config.listValues is set, each entry also carries its fully resolved
+// value -- running the same resolveParam a real expansion would, so a
+// default/operator chain shows what it actually produces, not just the bare
+// variable's raw value -- or an explicit unset sentinel when the variable is
+// unset and has no fallback to supply one. When config.listUnique or
+// config.listMissing is set, listParams defers to listUniqueParams or
+// listMissingParams instead. It panics on the same errors Expand/GetOutput
+// would (an unset ${VAR:?msg}, or an undefined variable under --strict).
+func listParams(params []Param, payload []rune, config *Config) {
+	if config.listUnique {
+		listUniqueParams(params, config)
+		return
+	}
+	if config.listMissing {
+		listMissingParams(params, config)
+		return
+	}
+	if config.listValues && config.strict {
+		if err := checkStrict(params, config); err != nil {
+			panic(err)
+		}
+	}
+
+	var newlines []int
+	if payload != nil {
+		newlines = newlineOffsets(payload)
+	}
+
+	entries := make([]ParamJson, 0, len(params))
+	for _, p := range params {
+		entry := ParamJson{Param: p.Raw, Index: p.Position[0], End: p.Position[1]}
+		if payload != nil {
+			entry.ByteIndex = runeToByteOffset(payload, p.Position[0])
+			entry.ByteEnd = runeToByteOffset(payload, p.Position[1])
+			entry.Line, entry.Column = lineAndColumn(newlines, p.Position[0])
+		}
+		if config.listValues {
+			_, ok := config.lookupVar(p.Name)
+			entry.Set = ok
+			value, err := resolveParam(p, config)
+			if err != nil {
+				panic(err)
+			}
+			if ok || value != "" {
+				entry.Value = &value
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	switch config.format {
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"param", "index"})
+		for _, e := range entries {
+			w.Write([]string{e.Param, fmt.Sprint(e.Index)})
+		}
+		w.Flush()
+	case "tab":
+		writeAligned(entries, config.align, "%s\t%d\n")
+	case "plain":
+		for _, e := range entries {
+			fmt.Printf("%d\t%s\n", e.Index, e.Param)
+		}
+	case "name-value":
+		writeAligned(entries, config.align, "%s = %d\n")
+	case "yaml":
+		data, _ := yaml.Marshal(entries)
+		os.Stdout.Write(data)
+	default:
+		data, _ := json.Marshal(entries)
+		fmt.Println(string(data))
+	}
+}
+
+// runeToByteOffset converts a rune index into payload to the corresponding
+// UTF-8 byte offset.
+func runeToByteOffset(payload []rune, runeIdx int) int {
+	return len(string(payload[:runeIdx]))
+}
+
+// newlineOffsets returns the rune offset of every '\n' in payload, in
+// ascending order, for lineAndColumn to binary search against.
+func newlineOffsets(payload []rune) []int {
+	var offsets []int
+	for i, r := range payload {
+		if r == '\n' {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// lineAndColumn converts a rune offset into a 1-based line/column pair
+// (both counted in runes), given newlines (the result of newlineOffsets
+// for the same payload).
+func lineAndColumn(newlines []int, offset int) (line, column int) {
+	line = sort.Search(len(newlines), func(i int) bool { return newlines[i] >= offset }) + 1
+	lineStart := 0
+	if line > 1 {
+		lineStart = newlines[line-2] + 1
+	}
+	return line, offset - lineStart + 1
+}
+
+// ParamOccurrence is the per-variable record emitted by --list --unique,
+// collapsing every occurrence sharing a Param.Id down to one entry with a
+// Count of how many times it was referenced.
+type ParamOccurrence struct {
+	Param string `json:"param" yaml:"param"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+// listUniqueParams renders one entry per distinct Param.Id, sorted by its
+// raw text, with a Count of how many times that id occurred in params. It's
+// listParams' counterpart for --list --unique, sharing the same
+// config.format switch.
+func listUniqueParams(params []Param, config *Config) {
+	index := make(map[string]int)
+	var entries []ParamOccurrence
+	for _, p := range params {
+		if i, ok := index[p.Id]; ok {
+			entries[i].Count++
+			continue
+		}
+		index[p.Id] = len(entries)
+		entries = append(entries, ParamOccurrence{Param: p.Raw, Count: 1})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Param < entries[j].Param })
+
+	switch config.format {
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"param", "count"})
+		for _, e := range entries {
+			w.Write([]string{e.Param, fmt.Sprint(e.Count)})
+		}
+		w.Flush()
+	case "tab":
+		writeAlignedCounts(entries, config.align, "%s\t%d\n")
+	case "plain":
+		for _, e := range entries {
+			fmt.Printf("%d\t%s\n", e.Count, e.Param)
+		}
+	case "name-value":
+		writeAlignedCounts(entries, config.align, "%s = %d\n")
+	case "yaml":
+		data, _ := yaml.Marshal(entries)
+		os.Stdout.Write(data)
+	default:
+		data, _ := json.Marshal(entries)
+		fmt.Println(string(data))
+	}
+}
+
+// listMissingParams filters params down to the distinct variable names that
+// are currently unset and have no operator supplying a fallback -- the same
+// check --strict runs to fail the expansion, but reported here instead of
+// enforced.
+func listMissingParams(params []Param, config *Config) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range params {
+		if p.Indirect || p.Operator == "@prefix" || p.Operator == "@profile" || strictExemptOperators[p.Operator] || seen[p.Name] {
+			continue
+		}
+		if _, ok := config.lookupVar(p.Name); !ok {
+			seen[p.Name] = true
+			names = append(names, p.Name)
+		}
+	}
+
+	switch config.format {
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"param"})
+		for _, n := range names {
+			w.Write([]string{n})
+		}
+		w.Flush()
+	case "tab", "plain", "name-value":
+		for _, n := range names {
+			fmt.Println(n)
+		}
+	case "yaml":
+		data, _ := yaml.Marshal(names)
+		os.Stdout.Write(data)
+	default:
+		data, _ := json.Marshal(names)
+		fmt.Println(string(data))
+	}
+}
+
+// writeAlignedCounts is writeAligned's counterpart for ParamOccurrence
+// entries, printing format with each entry's param name then its count.
+func writeAlignedCounts(entries []ParamOccurrence, align bool, format string) {
+	width := 0
+	if align {
+		for _, e := range entries {
+			if n := len([]rune(e.Param)); n > width {
+				width = n
+			}
+		}
+	}
+	for _, e := range entries {
+		name := e.Param
+		if align {
+			name = fmt.Sprintf("%-*s", width, name)
+		}
+		fmt.Printf(format, name, e.Count)
+	}
+}
+
+// writeAligned prints entries using format, a two-verb string taking the
+// param name then its index. When align is set, the name column is padded
+// to the widest param name so the index column lines up.
+func writeAligned(entries []ParamJson, align bool, format string) {
+	width := 0
+	if align {
+		for _, e := range entries {
+			if n := len([]rune(e.Param)); n > width {
+				width = n
+			}
+		}
+	}
+	for _, e := range entries {
+		name := e.Param
+		if align {
+			name = fmt.Sprintf("%-*s", width, name)
+		}
+		fmt.Printf(format, name, e.Index)
+	}
+}
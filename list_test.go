@@ -0,0 +1,347 @@
+package parry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	orig := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	fn()
+	w.Close()
+	os.Stdout = orig
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestListParamsAlignPadsNameColumn(t *testing.T) {
+	params := []Param{
+		{Raw: "$A", Position: [2]int{0, 2}},
+		{Raw: "${LONGNAME}", Position: [2]int{5, 16}},
+	}
+	config := NewConfig()
+	config.SetFormat("tab")
+	config.SetAlign(true)
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %q", len(lines), out)
+	}
+	nameA := strings.Split(lines[0], "\t")[0]
+	nameLong := strings.Split(lines[1], "\t")[0]
+	if len(nameA) != len(nameLong) {
+		t.Fatalf("expected aligned name columns of equal width, got %q and %q", nameA, nameLong)
+	}
+}
+
+func TestListParamsValuesDistinguishUnsetFromSetEmpty(t *testing.T) {
+	os.Unsetenv("UNSET_VAR")
+	os.Setenv("EMPTY_VAR", "")
+	defer os.Unsetenv("EMPTY_VAR")
+
+	params := []Param{
+		{Raw: "$UNSET_VAR", Name: "UNSET_VAR", Position: [2]int{0, 10}},
+		{Raw: "$EMPTY_VAR", Name: "EMPTY_VAR", Position: [2]int{11, 21}},
+	}
+	config := NewConfig()
+	config.SetListValues(true)
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	var entries []ParamJson
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, out)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Value != nil || entries[0].Set {
+		t.Fatalf("expected unset entry to have nil value and set=false, got %+v", entries[0])
+	}
+	if entries[1].Value == nil || *entries[1].Value != "" || !entries[1].Set {
+		t.Fatalf("expected set-empty entry to have value=\"\" and set=true, got %+v", entries[1])
+	}
+}
+
+func TestListParamsValuesShowResolvedDefaultNotRawLookup(t *testing.T) {
+	os.Unsetenv("LIST_DEFAULTED")
+
+	payload := []rune("${LIST_DEFAULTED:-fallback}")
+	params, err := findParams(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := NewConfig()
+	config.SetListValues(true)
+
+	out := captureStdout(t, func() {
+		listParams(params, payload, config)
+	})
+
+	var entries []ParamJson
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, out)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].Set {
+		t.Fatalf("expected the underlying variable to be reported unset, got %+v", entries[0])
+	}
+	if entries[0].Value == nil || *entries[0].Value != "fallback" {
+		t.Fatalf("expected the resolved default \"fallback\", got %+v", entries[0])
+	}
+}
+
+func TestListParamsJSONIncludesEndAndByteOffsets(t *testing.T) {
+	os.Unsetenv("LIST_END_VAR")
+
+	payload := []rune("héllo ${LIST_END_VAR}")
+	params, err := findParams(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := NewConfig()
+
+	out := captureStdout(t, func() {
+		listParams(params, payload, config)
+	})
+
+	var entries []ParamJson
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, out)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Index != 6 || entry.End != 21 {
+		t.Fatalf("expected rune span [6,21), got [%d,%d)", entry.Index, entry.End)
+	}
+	// "é" is 2 bytes in UTF-8, so the byte offsets run one ahead of the
+	// rune offsets from that point on.
+	if entry.ByteIndex != 7 || entry.ByteEnd != 22 {
+		t.Fatalf("expected byte span [7,22), got [%d,%d)", entry.ByteIndex, entry.ByteEnd)
+	}
+}
+
+func TestListParamsJSONIncludesLineAndColumn(t *testing.T) {
+	os.Unsetenv("LIST_LINE_VAR")
+
+	payload := []rune("first\nsecond ${LIST_LINE_VAR}\nthird")
+	params, err := findParams(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := NewConfig()
+
+	out := captureStdout(t, func() {
+		listParams(params, payload, config)
+	})
+
+	var entries []ParamJson
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, out)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Line != 2 || entry.Column != 8 {
+		t.Fatalf("expected line 2, column 8, got line %d, column %d", entry.Line, entry.Column)
+	}
+}
+
+func TestListParamsYAMLFormatMarshalsEntries(t *testing.T) {
+	params := []Param{
+		{Raw: "$A", Name: "A", Position: [2]int{0, 2}},
+	}
+	config := NewConfig()
+	config.SetFormat("yaml")
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	var entries []ParamJson
+	if err := yaml.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, out)
+	}
+	if len(entries) != 1 || entries[0].Param != "$A" || entries[0].Index != 0 {
+		t.Fatalf("got %+v", entries)
+	}
+}
+
+func TestListParamsPlainFormatPrintsIndexTabParam(t *testing.T) {
+	params := []Param{
+		{Raw: "$A", Name: "A", Position: [2]int{0, 2}},
+		{Raw: "${B}", Name: "B", Position: [2]int{10, 14}},
+	}
+	config := NewConfig()
+	config.SetFormat("plain")
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	want := "0\t$A\n10\t${B}\n"
+	if out != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+}
+
+func TestListUniqueParamsCollapsesToDistinctIdsWithCount(t *testing.T) {
+	params := []Param{
+		{Raw: "$Q", Name: "Q", Id: "$Q", Position: [2]int{0, 2}},
+		{Raw: "$A", Name: "A", Id: "$A", Position: [2]int{3, 5}},
+		{Raw: "$Q", Name: "Q", Id: "$Q", Position: [2]int{6, 8}},
+		{Raw: "$Q", Name: "Q", Id: "$Q", Position: [2]int{9, 11}},
+	}
+	config := NewConfig()
+	config.SetListMode(true)
+	config.SetListUnique(true)
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	var entries []ParamOccurrence
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, out)
+	}
+	want := []ParamOccurrence{{Param: "$A", Count: 1}, {Param: "$Q", Count: 3}}
+	if len(entries) != len(want) || entries[0] != want[0] || entries[1] != want[1] {
+		t.Fatalf("got %+v, want %+v", entries, want)
+	}
+}
+
+func TestListUniqueParamsPlainFormat(t *testing.T) {
+	params := []Param{
+		{Raw: "$Q", Name: "Q", Id: "$Q", Position: [2]int{0, 2}},
+		{Raw: "$Q", Name: "Q", Id: "$Q", Position: [2]int{6, 8}},
+	}
+	config := NewConfig()
+	config.SetListMode(true)
+	config.SetListUnique(true)
+	config.SetFormat("plain")
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	if out != "2\t$Q\n" {
+		t.Fatalf("got %q, want %q", out, "2\t$Q\n")
+	}
+}
+
+func TestListMissingParamsFiltersToUnsetWithoutDefault(t *testing.T) {
+	os.Unsetenv("LIST_MISSING_UNSET")
+	os.Setenv("LIST_MISSING_SET", "value")
+	defer os.Unsetenv("LIST_MISSING_SET")
+
+	params := []Param{
+		{Raw: "$LIST_MISSING_UNSET", Name: "LIST_MISSING_UNSET", Id: "$LIST_MISSING_UNSET"},
+		{Raw: "$LIST_MISSING_SET", Name: "LIST_MISSING_SET", Id: "$LIST_MISSING_SET"},
+		{Raw: "${LIST_MISSING_DEFAULTED:-fallback}", Name: "LIST_MISSING_DEFAULTED", Id: "${LIST_MISSING_DEFAULTED:-fallback}", Operator: ":-", Operand: "fallback"},
+	}
+	config := NewConfig()
+	config.SetListMode(true)
+	config.SetListMissing(true)
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	var names []string
+	if err := json.Unmarshal([]byte(out), &names); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, out)
+	}
+	want := []string{"LIST_MISSING_UNSET"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("got %v, want %v", names, want)
+	}
+}
+
+func TestListMissingParamsExemptsProfileReferences(t *testing.T) {
+	os.Unsetenv("LIST_MISSING_PROFILE_VAR")
+
+	params := []Param{
+		{Raw: "${@profile:prod:LIST_MISSING_PROFILE_VAR}", Name: "LIST_MISSING_PROFILE_VAR", Id: "${@profile:prod:LIST_MISSING_PROFILE_VAR}", Operator: "@profile", Profile: "prod"},
+	}
+	config := NewConfig()
+	config.SetListMode(true)
+	config.SetListMissing(true)
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	var names []string
+	if err := json.Unmarshal([]byte(out), &names); err != nil {
+		t.Fatalf("unmarshal: %v\noutput: %s", err, out)
+	}
+	if len(names) != 0 {
+		t.Fatalf("got %v, want no missing names -- @profile resolves against a profile layer, not the active environment", names)
+	}
+}
+
+func TestListParamsPlainFormatPrintsNothingForNoParams(t *testing.T) {
+	config := NewConfig()
+	config.SetFormat("plain")
+
+	out := captureStdout(t, func() {
+		listParams(nil, nil, config)
+	})
+
+	if out != "" {
+		t.Fatalf("got %q, want empty output", out)
+	}
+}
+
+func TestConfigValidateRejectsUnknownFormat(t *testing.T) {
+	config := NewConfig()
+	config.SetFormat("xml")
+	if err := config.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown list format")
+	}
+}
+
+func TestListParamsNoAlignLeavesColumnsUnpadded(t *testing.T) {
+	params := []Param{
+		{Raw: "$A", Position: [2]int{0, 2}},
+		{Raw: "${LONGNAME}", Position: [2]int{5, 16}},
+	}
+	config := NewConfig()
+	config.SetFormat("tab")
+
+	out := captureStdout(t, func() {
+		listParams(params, nil, config)
+	})
+
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	nameA := strings.Split(lines[0], "\t")[0]
+	if nameA != "$A" {
+		t.Fatalf("expected unpadded name %q, got %q", "$A", nameA)
+	}
+}
@@ -0,0 +1,65 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMaxFileSizeRejectsFileOverLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tmpl")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 101)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.SetMaxFileSize(100)
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("expected a panic for a file over the size limit")
+		}
+		if _, ok := r.(error); !ok {
+			t.Fatalf("got panic value %v, want an error", r)
+		}
+	}()
+	captureStdout(t, func() { GetOutput(cfg) })
+}
+
+func TestMaxFileSizeAllowsFileUnderLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tmpl")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 99)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.SetMaxFileSize(100)
+
+	got := captureStdout(t, func() { GetOutput(cfg) })
+	if got != strings.Repeat("x", 99) {
+		t.Fatalf("got %q, want 99 x's", got)
+	}
+}
+
+func TestReadAllRejectsStreamOverLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data")
+	if err := os.WriteFile(path, []byte(strings.Repeat("y", 101)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := readAll(f, 16, 100); err == nil {
+		t.Fatal("expected an error for a stream exceeding the max size")
+	}
+}
@@ -0,0 +1,76 @@
+package parry
+
+import "testing"
+
+func TestMaxValueLengthTruncatesOverLimit(t *testing.T) {
+	t.Setenv("MAXLEN_LONG", "abcdefghij")
+	cfg := NewConfig()
+	cfg.SetMaxValueLength(5)
+
+	got := mustExpand(t, "$MAXLEN_LONG", cfg)
+	if got != "abcde" {
+		t.Fatalf("got %q, want %q", got, "abcde")
+	}
+}
+
+func TestMaxValueLengthTruncatesWithMarker(t *testing.T) {
+	t.Setenv("MAXLEN_LONG", "abcdefghij")
+	cfg := NewConfig()
+	cfg.SetMaxValueLength(5)
+	cfg.SetTruncationMarker("...")
+
+	got := mustExpand(t, "$MAXLEN_LONG", cfg)
+	if got != "abcde..." {
+		t.Fatalf("got %q, want %q", got, "abcde...")
+	}
+}
+
+func TestMaxValueLengthLeavesValueUnderLimitAlone(t *testing.T) {
+	t.Setenv("MAXLEN_SHORT", "abc")
+	cfg := NewConfig()
+	cfg.SetMaxValueLength(5)
+
+	got := mustExpand(t, "$MAXLEN_SHORT", cfg)
+	if got != "abc" {
+		t.Fatalf("got %q, want %q", got, "abc")
+	}
+}
+
+func TestMaxValueLengthErrorPolicyFailsOverLimit(t *testing.T) {
+	t.Setenv("MAXLEN_LONG", "abcdefghij")
+	cfg := NewConfig()
+	cfg.SetMaxValueLength(5)
+	cfg.SetMaxValueLengthPolicy("error")
+
+	_, err := mustExpandErr(t, "$MAXLEN_LONG", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Position != 0 {
+		t.Fatalf("got position %d, want 0", pe.Position)
+	}
+}
+
+func TestMaxValueLengthErrorPolicyPassesUnderLimit(t *testing.T) {
+	t.Setenv("MAXLEN_SHORT", "abc")
+	cfg := NewConfig()
+	cfg.SetMaxValueLength(5)
+	cfg.SetMaxValueLengthPolicy("error")
+
+	got, err := mustExpandErr(t, "$MAXLEN_SHORT", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "abc" {
+		t.Fatalf("got %q, want %q", got, "abc")
+	}
+}
+
+func TestConfigValidateRejectsUnknownMaxValueLengthPolicy(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetMaxValueLengthPolicy("bogus")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown max-value-length policy")
+	}
+}
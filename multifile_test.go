@@ -0,0 +1,67 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetOutputConcatenatesMultipleFilesInOrder(t *testing.T) {
+	t.Setenv("MULTI_VAR", "x")
+	dir := t.TempDir()
+
+	a := filepath.Join(dir, "a.tmpl")
+	b := filepath.Join(dir, "b.tmpl")
+	if err := os.WriteFile(a, []byte("first $MULTI_VAR\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("second $MULTI_VAR\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(a)
+	cfg.AddFile(b)
+
+	got := captureStdout(t, func() { GetOutput(cfg) })
+	want := "first x\nsecond x\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestGetOutputEditsEachFileInPlaceIndependently(t *testing.T) {
+	t.Setenv("MULTI_IP_VAR", "y")
+	dir := t.TempDir()
+
+	a := filepath.Join(dir, "a.tmpl")
+	b := filepath.Join(dir, "b.tmpl")
+	if err := os.WriteFile(a, []byte("a=$MULTI_IP_VAR"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("b=$MULTI_IP_VAR"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(a)
+	cfg.AddFile(b)
+	cfg.SetEditInPlace(true)
+
+	GetOutput(cfg)
+
+	gotA, err := os.ReadFile(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotB, err := os.ReadFile(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotA) != "a=y" {
+		t.Fatalf("a: got %q, want %q", gotA, "a=y")
+	}
+	if string(gotB) != "b=y" {
+		t.Fatalf("b: got %q, want %q", gotB, "b=y")
+	}
+}
@@ -0,0 +1,64 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProcessFileFastPathCopiesContentWithNoDollarSignUnchanged(t *testing.T) {
+	tmpl := "plain text, no variables here.\nsecond line.\n"
+	got := runGetOutput(t, tmpl, func(c *Config) {})
+	if got != tmpl {
+		t.Fatalf("got %q, want %q", got, tmpl)
+	}
+}
+
+func TestProcessFileFastPathStillHonorsEnsureFinalNewline(t *testing.T) {
+	got := runGetOutput(t, "no newline at eof", func(c *Config) {
+		c.SetEnsureFinalNewline(true)
+	})
+	if got != "no newline at eof\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestProcessFileFastPathStillHonorsDedupeOutputBlankLines(t *testing.T) {
+	got := runGetOutput(t, "first\n\n\n\n\nsecond\n", func(c *Config) {
+		c.SetDedupeOutputBlankLines(true)
+	})
+	if got != "first\n\nsecond\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestProcessFileFastPathStillHonorsListMode(t *testing.T) {
+	got := runGetOutput(t, "no variables here", func(c *Config) {
+		c.SetListMode(true)
+	})
+	if got != "[]\n" {
+		t.Fatalf("got %q, want %q (empty JSON listing)", got, "[]\n")
+	}
+}
+
+func BenchmarkGetOutputNoDollarFastPath(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 50000; i++ {
+		sb.WriteString("a line with no variables at all, just plain text padding.\n")
+	}
+	dir := b.TempDir()
+	tmpl := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(tmpl, []byte(sb.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	out := filepath.Join(dir, "out.txt")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cfg := NewConfig()
+		cfg.AddFile(tmpl)
+		cfg.SetOutput(out)
+		GetOutput(cfg)
+	}
+}
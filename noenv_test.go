@@ -0,0 +1,50 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetOutputNoEnvIgnoresInheritedProcessVariable(t *testing.T) {
+	os.Setenv("NOENV_INHERITED", "from-process")
+	defer os.Unsetenv("NOENV_INHERITED")
+
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("${NOENV_INHERITED:-fallback}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetNoEnv(true)
+
+	if got := captureStdout(t, func() { GetOutput(cfg) }); got != "fallback" {
+		t.Fatalf("got %q, want %q", got, "fallback")
+	}
+}
+
+func TestGetOutputNoEnvStillResolvesEnvfileAndOverrides(t *testing.T) {
+	os.Unsetenv("NOENV_OVERRIDE")
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("NOENV_FILE=from-envfile\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("$NOENV_FILE $NOENV_OVERRIDE"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetNoEnv(true)
+	cfg.SetEnvFile(filepath.Join(dir, ".env"))
+	cfg.AddEnv("NOENV_OVERRIDE=from-override")
+
+	want := "from-envfile from-override"
+	if got := captureStdout(t, func() { GetOutput(cfg) }); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
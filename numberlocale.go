@@ -0,0 +1,39 @@
+package parry
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// scaleOf returns the number of digits after the decimal point in value's
+// textual representation (0 if there's no "."), so formatNumberLocale can
+// tell number.Decimal to preserve exactly that many fractional digits
+// instead of silently rounding to its default of 3.
+func scaleOf(value string) int {
+	_, frac, found := strings.Cut(value, ".")
+	if !found {
+		return 0
+	}
+	return len(frac)
+}
+
+// formatNumberLocale reformats value's digit grouping and decimal separator
+// for locale (a BCP 47 tag, e.g. "de" or "en"), as selected by
+// --number-locale. value is left untouched when it doesn't parse cleanly as
+// a number -- this never turns a non-numeric value into one -- or when
+// locale isn't a tag language.Parse recognizes.
+func formatNumberLocale(value, locale string) string {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return value
+	}
+	return message.NewPrinter(tag).Sprintf("%v", number.Decimal(f, number.MaxFractionDigits(scaleOf(value))))
+}
@@ -0,0 +1,49 @@
+package parry
+
+import "testing"
+
+func TestNumberLocaleFormatsGermanSeparators(t *testing.T) {
+	got := formatNumberLocale("1234.5", "de")
+	want := "1.234,5"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestNumberLocalePreservesFractionalDigitsBeyondThree(t *testing.T) {
+	got := formatNumberLocale("3.14159265358979", "de")
+	want := "3,14159265358979"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestNumberLocaleLeavesNonNumericValueUnchanged(t *testing.T) {
+	got := formatNumberLocale("not-a-number", "de")
+	if got != "not-a-number" {
+		t.Fatalf("got %q, want unchanged", got)
+	}
+}
+
+func TestNumberLocaleAppliesDuringExpansion(t *testing.T) {
+	t.Setenv("NUMBER_LOCALE_VAL", "1234.5")
+	cfg := NewConfig()
+	cfg.SetNumberLocale("de")
+
+	got := mustExpand(t, "price=$NUMBER_LOCALE_VAL", cfg)
+	want := "price=1.234,5"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithoutNumberLocaleValueIsUnchanged(t *testing.T) {
+	t.Setenv("NUMBER_LOCALE_DEFAULT", "1234.5")
+	cfg := NewConfig()
+
+	got := mustExpand(t, "price=$NUMBER_LOCALE_DEFAULT", cfg)
+	want := "price=1234.5"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package parry
+
+import (
+	"regexp"
+	"strings"
+)
+
+// jsonSimpleValueLine matches a line containing nothing but a single JSON
+// `"key": "value"` pair, optionally trailing a comma -- the only shape
+// --omit-empty-json recognizes. Anything more structurally nested (arrays,
+// multiple pairs per line, multi-line values) is left untouched.
+var jsonSimpleValueLine = regexp.MustCompile(`^\s*"[^"\\]+"\s*:\s*"(?:\\.|[^"\\])*"\s*,?\s*$`)
+
+// omitEmptyJSONLines drops each line of output whose original template
+// line was a simple `"key": "$VAR"` pair (matching jsonSimpleValueLine)
+// where $VAR was the line's param and resolved to the empty string. It
+// works line-by-line against the original payload so it can tell an
+// emptied param apart from a value that was always `""` in the template.
+func omitEmptyJSONLines(payload []rune, params []Param, values map[string]*lazyValue, output string) string {
+	origLines := strings.Split(string(payload), "\n")
+	drop := make(map[int]bool)
+
+	lineIdx, scanned := 0, 0
+	for _, line := range origLines {
+		lineEnd := scanned + len([]rune(line))
+		for _, p := range params {
+			if p.Position[0] < scanned || p.Position[0] >= lineEnd {
+				continue
+			}
+			// reassemble has already resolved every param by this point
+			// (omitEmptyJSONLines only runs after it), so get just
+			// returns the cached value; the error was already handled.
+			if value, _ := values[p.Id].get(); value != "" {
+				continue
+			}
+			if jsonSimpleValueLine.MatchString(line) {
+				drop[lineIdx] = true
+			}
+		}
+		scanned = lineEnd + 1 // account for the '\n' split away
+		lineIdx++
+	}
+
+	if len(drop) == 0 {
+		return output
+	}
+
+	outLines := strings.Split(output, "\n")
+	if len(outLines) != len(origLines) {
+		// A resolved value introduced or removed a newline somewhere;
+		// the line-index mapping no longer holds, so leave output as-is
+		// rather than risk dropping the wrong line.
+		return output
+	}
+
+	kept := make([]string, 0, len(outLines))
+	for i, line := range outLines {
+		if drop[i] {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
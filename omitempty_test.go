@@ -0,0 +1,36 @@
+package parry
+
+import "testing"
+
+func TestOmitEmptyJSONDropsLineForUnsetVar(t *testing.T) {
+	tmpl := "{\n  \"name\": \"app\",\n  \"token\": \"$UNSET_TOKEN\",\n  \"port\": \"8080\"\n}\n"
+	got := runGetOutput(t, tmpl, func(c *Config) {
+		c.SetOmitEmptyJSON(true)
+	})
+	want := "{\n  \"name\": \"app\",\n  \"port\": \"8080\"\n}\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestOmitEmptyJSONKeepsLiterallyEmptyValue(t *testing.T) {
+	tmpl := "{\n  \"name\": \"\"\n}\n"
+	got := runGetOutput(t, tmpl, func(c *Config) {
+		c.SetOmitEmptyJSON(true)
+	})
+	if got != tmpl {
+		t.Fatalf("got %q, want %q (literal empty string shouldn't be touched)", got, tmpl)
+	}
+}
+
+func TestOmitEmptyJSONKeepsLineWhenVarIsSet(t *testing.T) {
+	t.Setenv("OMIT_EMPTY_SET", "hello")
+	tmpl := "{\n  \"greeting\": \"$OMIT_EMPTY_SET\"\n}\n"
+	got := runGetOutput(t, tmpl, func(c *Config) {
+		c.SetOmitEmptyJSON(true)
+	})
+	want := "{\n  \"greeting\": \"hello\"\n}\n"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
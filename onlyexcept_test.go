@@ -0,0 +1,59 @@
+package parry
+
+import "testing"
+
+func TestOnlyExpandsOnlyListedParams(t *testing.T) {
+	t.Setenv("ONE", "1")
+	t.Setenv("TWO", "2")
+	cfg := NewConfig()
+	cfg.AddOnly("ONE")
+
+	got := mustExpand(t, "a=$ONE b=$TWO", cfg)
+	want := "a=1 b=$TWO"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestOnlyAcceptsCommaSeparatedAndRepeatedForms(t *testing.T) {
+	t.Setenv("ONE", "1")
+	t.Setenv("TWO", "2")
+	t.Setenv("THREE", "3")
+	cfg := NewConfig()
+	cfg.AddOnly("ONE,TWO")
+	cfg.AddOnly("THREE")
+
+	got := mustExpand(t, "$ONE $TWO $THREE", cfg)
+	want := "1 2 3"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestExceptLeavesNamedParamsLiteral(t *testing.T) {
+	t.Setenv("PUBLIC", "ok")
+	t.Setenv("SECRET", "hunter2")
+	cfg := NewConfig()
+	cfg.AddExcept("SECRET")
+
+	got := mustExpand(t, "a=$PUBLIC b=$SECRET", cfg)
+	want := "a=ok b=$SECRET"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestOnlyAndExceptComposeWithPrefix(t *testing.T) {
+	t.Setenv("PARRY_NAME", "app")
+	t.Setenv("PARRY_SECRET", "hunter2")
+	t.Setenv("OTHER", "value")
+	cfg := NewConfig()
+	cfg.SetPrefix("PARRY_")
+	cfg.AddExcept("PARRY_SECRET")
+
+	got := mustExpand(t, "a=$PARRY_NAME b=$PARRY_SECRET c=$OTHER", cfg)
+	want := "a=app b=$PARRY_SECRET c=$OTHER"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
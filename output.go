@@ -0,0 +1,517 @@
+package parry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolvePath joins path onto cfg.baseDir when path is relative and a base
+// dir is set, leaving path untouched otherwise -- including "" (stdin) and
+// an already-absolute path.
+func resolvePath(path string, cfg *Config) string {
+	if path == "" || cfg == nil || cfg.baseDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cfg.baseDir, path)
+}
+
+// readFileOrStdin reads file fully into memory and decodes it as runes. An
+// empty file means stdin. When config.maxFileSize is set, a file input is
+// rejected up front via os.Stat, and stdin is rejected as soon as readAll
+// has read past the limit.
+func readFileOrStdin(file string, config *Config) []rune {
+	return []rune(string(readBytesOrStdin(file, config)))
+}
+
+// readBytesOrStdin is readFileOrStdin without the []rune decode, for
+// callers that can work with the raw bytes directly (or need to decide
+// whether decoding is even worth it, e.g. processFile's no-$ fast path).
+// A leading UTF-8 BOM is removed when config.stripBOM is set, whether the
+// input is a file or stdin.
+func readBytesOrStdin(file string, config *Config) []byte {
+	var data []byte
+	var err error
+	if file != "" {
+		if err := checkFileSize(file, config.maxFileSize); err != nil {
+			panic(err)
+		}
+		data, err = os.ReadFile(file)
+		err = wrapFileError(file, err)
+	} else {
+		data, err = readAll(os.Stdin, config.readBufferSize(), config.maxFileSize)
+	}
+	if err != nil {
+		panic(err)
+	}
+	if config.stripBOM {
+		data = stripUTF8BOM(data)
+	}
+	return data
+}
+
+// checkFileSize stats path and returns a *ParseError if it exceeds limit.
+// limit <= 0 means no limit.
+func checkFileSize(path string, limit int64) error {
+	if limit <= 0 {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > limit {
+		return &ParseError{
+			Fragment: path,
+			Position: -1,
+			Message:  fmt.Sprintf("file size %d bytes exceeds max-file-size of %d bytes", info.Size(), limit),
+		}
+	}
+	return nil
+}
+
+// ambientSnapshot captures the process environment as it was before parry
+// applies any --envfile/-e layers, for --report's precedence trace.
+func ambientSnapshot() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, found := strings.Cut(kv, "=")
+		if found {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// defaultReadBufferSize is the chunk size readAll uses when --read-buffer
+// wasn't given.
+const defaultReadBufferSize = 4096
+
+// readAll reads f to completion in bufSize-sized chunks. When maxSize is
+// positive, it stops and returns a *ParseError as soon as the running total
+// exceeds it, instead of reading the rest of a too-large input into memory.
+func readAll(f *os.File, bufSize int, maxSize int64) ([]byte, error) {
+	if bufSize <= 0 {
+		bufSize = defaultReadBufferSize
+	}
+	buf := make([]byte, 0, bufSize)
+	tmp := make([]byte, bufSize)
+	for {
+		n, err := f.Read(tmp)
+		if n > 0 {
+			buf = append(buf, tmp[:n]...)
+			if maxSize > 0 && int64(len(buf)) > maxSize {
+				return nil, &ParseError{
+					Fragment: f.Name(),
+					Position: -1,
+					Message:  fmt.Sprintf("input exceeds max-file-size of %d bytes", maxSize),
+				}
+			}
+		}
+		if err != nil {
+			if err.Error() == "EOF" {
+				return buf, nil
+			}
+			return buf, err
+		}
+		if n == 0 {
+			return buf, nil
+		}
+	}
+}
+
+// GetOutput runs the full tokenize -> findParams -> resolve -> reassemble
+// pipeline for config and writes the result to stdout (or in place, when
+// config.editInPlace is set). With no files configured it reads stdin once;
+// with one or more files it processes each independently, in order --
+// rewriting each in place under -i, or concatenating their expanded output
+// to stdout otherwise. Errors surface as panics; the CLI entry point
+// recovers them.
+func GetOutput(config *Config) {
+	if config.editInPlace && config.output != "" {
+		panic(&ParseError{Position: -1, Message: "-o/--output cannot be combined with -i"})
+	}
+	if config.teePath != "" && config.editInPlace {
+		panic(&ParseError{Position: -1, Message: "--tee cannot be combined with -i, which never writes to stdout"})
+	}
+	if config.teePath != "" && config.output != "" {
+		panic(&ParseError{Position: -1, Message: "--tee cannot be combined with -o/--output, which already redirects stdout"})
+	}
+
+	env := newEnvironment()
+	env.setAmbient(ambientSnapshot())
+
+	// restricted collects every --envfile/--envtoml/-e value when noEnv is
+	// set, instead of the values being applied to the process environment,
+	// so resolution can be pointed at exactly these sources via a Lookuper
+	// and never fall through to an inherited variable.
+	var restricted map[string]string
+	if config.noEnv {
+		restricted = make(map[string]string)
+	}
+	setEnv := func(name, value string) {
+		if restricted != nil {
+			restricted[name] = value
+			return
+		}
+		os.Setenv(name, value)
+	}
+
+	if config.envFile != "" {
+		envFile := resolvePath(config.envFile, config)
+		if err := checkFileSize(envFile, config.maxFileSize); err != nil {
+			panic(err)
+		}
+		values, err := parseEnvFile(envFile, config.nullData, config)
+		if err != nil {
+			panic(err)
+		}
+		env.addEnvFile(envFile, values)
+		for name, value := range values {
+			setEnv(name, value)
+		}
+	}
+
+	if config.envDir != "" {
+		envDir := resolvePath(config.envDir, config)
+		matches, err := filepath.Glob(filepath.Join(envDir, "*.env"))
+		if err != nil {
+			panic(err)
+		}
+		for _, envFile := range matches {
+			if err := checkFileSize(envFile, config.maxFileSize); err != nil {
+				panic(err)
+			}
+			values, err := parseEnvFile(envFile, config.nullData, config)
+			if err != nil {
+				panic(err)
+			}
+			env.addEnvFile(envFile, values)
+			profile := strings.TrimSuffix(filepath.Base(envFile), ".env")
+			if config.profiles == nil {
+				config.profiles = make(map[string]map[string]string)
+			}
+			config.profiles[profile] = values
+			for name, value := range values {
+				setEnv(name, value)
+			}
+		}
+	}
+
+	if config.envFileIf != "" {
+		cond, path, err := parseEnvFileIfSpec(config.envFileIf)
+		if err != nil {
+			panic(err)
+		}
+		getEnv := func(name string) (string, bool) {
+			if restricted != nil {
+				value, ok := restricted[name]
+				return value, ok
+			}
+			return os.LookupEnv(name)
+		}
+		if evalEnvFileIfCond(cond, getEnv) {
+			envFile := resolvePath(path, config)
+			if err := checkFileSize(envFile, config.maxFileSize); err != nil {
+				panic(err)
+			}
+			values, err := parseEnvFile(envFile, config.nullData, config)
+			if err != nil {
+				panic(err)
+			}
+			env.addEnvFile(envFile, values)
+			for name, value := range values {
+				setEnv(name, value)
+			}
+		}
+	}
+
+	if config.envTOMLFile != "" {
+		envTOMLFile := resolvePath(config.envTOMLFile, config)
+		if err := checkFileSize(envTOMLFile, config.maxFileSize); err != nil {
+			panic(err)
+		}
+		values, err := parseEnvTOMLFile(envTOMLFile, config.tomlTable)
+		if err != nil {
+			panic(err)
+		}
+		env.addEnvFile(envTOMLFile, values)
+		for name, value := range values {
+			setEnv(name, value)
+		}
+	}
+
+	overrides := make(map[string]string, len(config.envOverrides))
+	for _, assignment := range config.envOverrides {
+		name, value := parseEnvOverride(assignment)
+		overrides[name] = value
+		setEnv(name, value)
+	}
+	env.setOverride(overrides)
+
+	if restricted != nil {
+		config.SetLookuper(LookupFunc(func(name string) (string, bool) {
+			value, ok := restricted[name]
+			return value, ok
+		}))
+	}
+
+	files := config.files
+	if len(files) == 0 {
+		files = []string{""}
+	}
+
+	var out io.Writer = os.Stdout
+	if config.output != "" {
+		f, err := os.Create(config.output)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if config.teePath != "" {
+		f, err := os.Create(config.teePath)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		out = io.MultiWriter(out, f)
+	}
+
+	var referenced map[string]bool
+	if config.reportUnused {
+		referenced = make(map[string]bool)
+	}
+
+	for _, file := range files {
+		processFile(file, config, env, out, referenced)
+	}
+
+	if config.reportUnused {
+		for _, name := range unusedProvidedNames(env, referenced) {
+			fmt.Fprintln(os.Stderr, "unused variable (never referenced):", name)
+		}
+	}
+}
+
+// processFile runs the expand pipeline for a single file (or stdin, when
+// file is "") and writes its rendered result to out, unless config.report,
+// config.listMode, config.asPatch, or config.editInPlace send it elsewhere.
+// Rendering always completes in memory before anything reaches disk -- a
+// panic partway through (an unset ${VAR:?}, say) never touches the
+// original file under -i, since writeProcessedOutput/writeFileAtomically
+// only run once output is the final string. referenced, when non-nil (see
+// --report-unused), records every param name this file resolves against.
+func processFile(file string, config *Config, env *Environment, out io.Writer, referenced map[string]bool) {
+	file = resolvePath(file, config)
+
+	if config.convertEOLOnly {
+		writeProcessedOutput(file, config, out, string(convertEOL([]byte(string(readFileOrStdin(file, config))), config.stripBOM)))
+		return
+	}
+
+	raw := readBytesOrStdin(file, config)
+
+	if !bytes.ContainsRune(raw, '$') {
+		// Every param and every $'...' ANSI-C quote starts with a literal
+		// $, so a payload with none of those can't contain anything the
+		// rest of this pipeline would change -- skip the []rune round
+		// trip and the whole tokenize/findParams/reassemble pass
+		// entirely (the expensive part for a huge file with nothing to
+		// expand) and treat raw itself as both the source and the output.
+		if config.report {
+			data, _ := json.MarshalIndent(buildReport(env, nil), "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+		if config.listMode {
+			listParams(nil, nil, config)
+			return
+		}
+		if config.highlight {
+			fmt.Println(string(raw))
+			return
+		}
+		finishOutput(file, config, out, string(raw), nil, string(raw))
+		return
+	}
+
+	payload := []rune(string(raw))
+	validSlices, err := getValidSlices(payload, config)
+	if err != nil {
+		panic(err)
+	}
+
+	allParams, err := findParams(payload)
+	if err != nil {
+		panic(err)
+	}
+	params := filterParamsInRange(allParams, validSlices)
+	if config.atOffset >= 0 {
+		params = paramAtOffset(params, config.atOffset)
+	}
+	if referenced != nil {
+		for _, p := range params {
+			referenced[p.Name] = true
+		}
+	}
+
+	if config.report {
+		names := make([]string, 0, len(params))
+		for _, p := range params {
+			names = append(names, p.Name)
+		}
+		data, _ := json.MarshalIndent(buildReport(env, names), "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	if config.listMode {
+		listParams(params, payload, config)
+		return
+	}
+
+	if config.highlight {
+		fmt.Println(highlightParams(payload, params, shouldColorizeHighlight(config)))
+		return
+	}
+
+	var output string
+	if config.keepEmptyEnvLines {
+		output, err = expandEnvFormat(payload, config)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		values, err := mapParamValues(params, payload, config)
+		if err != nil {
+			panic(err)
+		}
+		output, err = reassemble(payload, params, values, config)
+		if err != nil {
+			panic(err)
+		}
+		if config.omitEmptyJSON {
+			output = omitEmptyJSONLines(payload, params, values, output)
+		}
+	}
+
+	finishOutput(file, config, out, string(payload), params, output)
+}
+
+// finishOutput applies the post-expansion steps shared by every
+// processFile path -- --write-env, --dedupe-output-blank-lines,
+// --ensure-final-newline, --patch, and finally the actual write (in place
+// or to out) -- given the already-expanded output and the params that
+// produced it. originalText is the unexpanded source, needed only for
+// --patch's diff.
+func finishOutput(file string, config *Config, out io.Writer, originalText string, params []Param, output string) {
+	if config.writeEnv != "" {
+		if err := writeEnvFile(config.writeEnv, params, config); err != nil {
+			panic(err)
+		}
+	}
+
+	if config.dedupeOutputBlankLines {
+		output = dedupeBlankLines(output)
+	}
+
+	if config.ensureFinalNewline && !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+
+	if config.asPatch {
+		label := file
+		if label == "" {
+			label = "stdin"
+		}
+		io.WriteString(out, unifiedDiff(label, originalText, output))
+		return
+	}
+
+	writeProcessedOutput(file, config, out, output)
+}
+
+// writeProcessedOutput writes a file's final rendered text either in place
+// (under -i, honoring --resolve-symlinks) or to out, the same way for every
+// processFile path -- the normal expand pipeline and --convert-eol-only's
+// tokenization-free one alike.
+func writeProcessedOutput(file string, config *Config, out io.Writer, output string) {
+	if config.editInPlace {
+		if file == "" {
+			panic(&ParseError{Position: -1, Message: "cannot edit stdin in place, -i requires a file"})
+		}
+		target := file
+		if config.resolveSymlinks {
+			if real, err := filepath.EvalSymlinks(target); err == nil {
+				target = real
+			}
+		}
+		backupSuffix := ""
+		if config.backup {
+			backupSuffix = config.backupSuffix
+			if backupSuffix == "" {
+				backupSuffix = ".bak"
+			}
+		}
+		if err := writeFileAtomically(target, output, backupSuffix); err != nil {
+			panic(err)
+		}
+		return
+	}
+	io.WriteString(out, output)
+}
+
+// writeFileAtomically writes output to a fresh temp file in target's
+// directory, carries over target's existing permission bits (0644 if
+// target doesn't exist yet), and renames the temp file over target. -i
+// would otherwise truncate a templated script in place, briefly exposing a
+// partially written file to a concurrent reader; renaming over it instead
+// makes the replacement atomic and keeps the original's executable bit.
+// A non-empty backupSuffix first copies target's pre-render bytes to
+// target+backupSuffix, so --backup's safety copy is always the original,
+// never a partially rendered one.
+func writeFileAtomically(target, output, backupSuffix string) error {
+	mode := os.FileMode(0644)
+	originalExists := false
+	if info, err := os.Stat(target); err == nil {
+		mode = info.Mode().Perm()
+		originalExists = true
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if backupSuffix != "" && originalExists {
+		original, err := os.ReadFile(target)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(target+backupSuffix, original, mode); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(output); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
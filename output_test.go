@@ -0,0 +1,270 @@
+package parry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runGetOutput(t *testing.T, content string, configure func(*Config)) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tmpl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	if configure != nil {
+		configure(cfg)
+	}
+	return captureStdout(t, func() {
+		GetOutput(cfg)
+	})
+}
+
+func TestEnsureFinalNewlineAppendsWhenMissing(t *testing.T) {
+	got := runGetOutput(t, "hello", func(c *Config) { c.SetEnsureFinalNewline(true) })
+	if !strings.HasSuffix(got, "\n") {
+		t.Fatalf("expected trailing newline, got %q", got)
+	}
+}
+
+func TestEnsureFinalNewlineLeavesExistingNewlineAlone(t *testing.T) {
+	got := runGetOutput(t, "hello\n", func(c *Config) { c.SetEnsureFinalNewline(true) })
+	if got != "hello\n" {
+		t.Fatalf("got %q, want %q", got, "hello\n")
+	}
+}
+
+func TestDefaultBehaviorIsByteExact(t *testing.T) {
+	got := runGetOutput(t, "hello", nil)
+	if got != "hello" {
+		t.Fatalf("got %q, want %q", got, "hello")
+	}
+}
+
+func TestResolveSymlinksPreservesLinkOnEditInPlace(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real")
+	link := filepath.Join(dir, "link")
+
+	os.Setenv("SYMLINK_VAR", "world")
+	defer os.Unsetenv("SYMLINK_VAR")
+
+	if err := os.WriteFile(target, []byte("hello $SYMLINK_VAR"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(link)
+	cfg.SetEditInPlace(true)
+	cfg.SetResolveSymlinks(true)
+
+	GetOutput(cfg)
+
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Fatal("expected link to remain a symlink after -i")
+	}
+
+	got, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello world" {
+		t.Fatalf("got %q, want %q", got, "hello world")
+	}
+}
+
+func TestEditInPlacePreservesExecutablePermission(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "script.sh")
+
+	os.Setenv("SCRIPT_VAR", "world")
+	defer os.Unsetenv("SCRIPT_VAR")
+
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho $SCRIPT_VAR"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.SetEditInPlace(true)
+	GetOutput(cfg)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Fatalf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0755))
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "#!/bin/sh\necho world" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestEditInPlaceLeavesOriginalUntouchedWhenRenderingPanics(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "in.tmpl")
+	original := "before ${MISSING_REQUIRED_EIP:?not set}"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("MISSING_REQUIRED_EIP")
+
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.SetEditInPlace(true)
+
+	func() {
+		defer func() { recover() }()
+		GetOutput(cfg)
+	}()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != original {
+		t.Fatalf("got %q, want the original %q untouched", got, original)
+	}
+}
+
+func TestEditInPlaceBackupDefaultSuffix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "in.tmpl")
+	original := "hello $BACKUP_VAR"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("BACKUP_VAR", "world")
+	defer os.Unsetenv("BACKUP_VAR")
+
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.SetEditInPlace(true)
+	cfg.SetBackup(true)
+	GetOutput(cfg)
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello world" {
+		t.Fatalf("got %q", got)
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != original {
+		t.Fatalf("got backup %q, want original %q", backup, original)
+	}
+}
+
+func TestEditInPlaceBackupCustomSuffix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "in.tmpl")
+	original := "hello $BACKUP_VAR2"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("BACKUP_VAR2", "world")
+	defer os.Unsetenv("BACKUP_VAR2")
+
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.SetEditInPlace(true)
+	cfg.SetBackup(true)
+	cfg.SetBackupSuffix(".orig")
+	GetOutput(cfg)
+
+	backup, err := os.ReadFile(path + ".orig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != original {
+		t.Fatalf("got backup %q, want original %q", backup, original)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Fatal("did not expect a default .bak backup when a custom suffix was set")
+	}
+}
+
+func TestReadAllIsCorrectAcrossBufferSizes(t *testing.T) {
+	content := strings.Repeat("0123456789", 1000) // 10000 bytes
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{1, 7, 4096, 65536} {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := readAll(f, size, 0)
+		f.Close()
+		if err != nil {
+			t.Fatalf("bufSize=%d: %v", size, err)
+		}
+		if string(got) != content {
+			t.Fatalf("bufSize=%d: got %d bytes, want %d", size, len(got), len(content))
+		}
+	}
+}
+
+func TestConfigValidateRejectsOutOfRangeReadBuffer(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetReadBuffer(-1)
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a negative read-buffer size")
+	}
+
+	cfg = NewConfig()
+	cfg.SetReadBuffer(maxReadBufferSize + 1)
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for a too-large read-buffer size")
+	}
+}
+
+func BenchmarkReadAll(b *testing.B) {
+	dir := b.TempDir()
+	path := filepath.Join(dir, "large")
+	content := []byte(strings.Repeat("x", 8<<20)) // 8 MiB
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, size := range []int{1 << 10, 4 << 10, 64 << 10, 1 << 20} {
+		b.Run(fmt.Sprintf("buffer=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				f, err := os.Open(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := readAll(f, size, 0); err != nil {
+					b.Fatal(err)
+				}
+				f.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetOutputWritesToNamedFileInsteadOfStdout(t *testing.T) {
+	t.Setenv("OUTPUT_FILE_VAR", "hi")
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	out := filepath.Join(dir, "out.conf")
+	if err := os.WriteFile(tmpl, []byte("value=$OUTPUT_FILE_VAR"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetOutput(out)
+
+	stdout := captureStdout(t, func() { GetOutput(cfg) })
+	if stdout != "" {
+		t.Fatalf("expected nothing on stdout, got %q", stdout)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "value=hi" {
+		t.Fatalf("got %q, want %q", got, "value=hi")
+	}
+}
+
+func TestSetOutputCombinedWithEditInPlacePanics(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetOutput(filepath.Join(dir, "out.conf"))
+	cfg.SetEditInPlace(true)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected a panic combining -o with -i")
+		}
+	}()
+	GetOutput(cfg)
+}
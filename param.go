@@ -0,0 +1,1044 @@
+package parry
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dlclark/regexp2"
+)
+
+// paramFinderPattern locates `$NAME` and `${...}` occurrences in a payload.
+// Within `${...}`, a backslash-escaped `\{` or `\}` is consumed as a
+// literal brace rather than ending the match or opening a nested one --
+// see unescapeBraces, which strips the backslash back out when building
+// the param's operand.
+const paramFinderPattern = `\$(?<bare>[A-Za-z_][A-Za-z0-9_]*)|\$\{(?<braced>(?:\\[{}]|[^{}]|\{[^{}]*\})*)\}`
+
+// paramFinderRegex is paramFinderPattern compiled once at package init,
+// rather than per findParams call -- regexp2 compilation isn't free, and
+// findParams runs on every Expand/GetOutput call.
+var paramFinderRegex = regexp2.MustCompile(paramFinderPattern, 0)
+
+// paramParserPattern pulls the name and, for braced params, the operator and
+// operand out of the inner text of a `${...}` expression. A name may
+// contain dots (e.g. `${Address.City}`), which only ExpandStruct's
+// reflection-based lookup gives meaning to -- everywhere else a dotted
+// name simply doesn't resolve, the same as any other undefined variable.
+const paramParserPattern = `^(?<name>[A-Za-z_][A-Za-z0-9_.]*)(?<op>:-|:\+|:\?|:=|%%|%|//|/|\^\^|\^|,,|,|-|\+|\?|=|:)?(?<operand>.*)$`
+
+// paramParserRegex is paramParserPattern compiled once at package init,
+// rather than per parseParam call -- parseParam runs once for every braced
+// param findParams turns up.
+var paramParserRegex = regexp2.MustCompile(paramParserPattern, 0)
+
+// Param describes a single `$NAME`/`${...}` occurrence found in a payload.
+type Param struct {
+	Raw      string // full matched text, e.g. "${FOO:-bar}"
+	Name     string // variable name, e.g. "FOO"
+	Id       string // dedupe key; identical expressions share one resolution
+	Braced   bool
+	Operator string // "", "-", ":-", "+", ":+", "?", ":?", "=", ":="
+	Operand  string // text following the operator, for default-style operators
+	Indirect bool   // true for `${!NAME...}`: resolve NAME first, then dereference
+	Position [2]int // rune offsets [start, end) within the payload
+
+	// Profile holds the profile name for `${@profile:NAME:VAR}`, set
+	// alongside Operator "@profile". See --env-dir and Config.profiles.
+	Profile string
+}
+
+// findParams scans payload and returns every recognized param occurrence, in
+// order of appearance.
+func findParams(payload []rune) ([]Param, error) {
+	re := paramFinderRegex
+	input := string(payload)
+
+	var params []Param
+	m, _ := re.FindStringMatch(input)
+	for m != nil {
+		// m.Index/m.Length are already rune offsets (regexp2 tracks
+		// positions in the []rune it converted input to internally), not
+		// byte offsets, so they carry over directly.
+		start := m.Index
+		end := start + len([]rune(m.String()))
+
+		var p Param
+		var err error
+		if g := m.GroupByName("braced"); g != nil && g.String() != "" {
+			p, err = parseParam(m.String(), g.String(), true)
+		} else if g := m.GroupByName("bare"); g != nil && g.String() != "" {
+			p, err = parseParam(m.String(), g.String(), false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.Position = [2]int{start, end}
+		params = append(params, p)
+
+		m, _ = re.FindNextMatch(m)
+	}
+	return params, nil
+}
+
+// parseParam builds a Param from the raw match text and its inner content.
+// For braced params, inner is the text between `{` and `}` and is further
+// split into name/operator/operand via paramParserPattern. It returns an
+// error so callers embedding parry don't need to catch a panic for
+// malformed input.
+func parseParam(raw, inner string, braced bool) (Param, error) {
+	p := Param{Raw: raw, Id: raw, Braced: braced}
+	if !braced {
+		p.Name = inner
+		return p, nil
+	}
+
+	if strings.HasPrefix(inner, "#") && inner != "#" {
+		p.Operator = "#"
+		p.Name = inner[1:]
+		return p, nil
+	}
+
+	if strings.HasPrefix(inner, "@prefix:") {
+		p.Operator = "@prefix"
+		p.Operand = unescapeBraces(strings.TrimPrefix(inner, "@prefix:"))
+		return p, nil
+	}
+
+	if strings.HasPrefix(inner, "@profile:") {
+		rest := strings.TrimPrefix(inner, "@profile:")
+		profile, name, ok := strings.Cut(rest, ":")
+		if !ok {
+			p.Name = inner
+			return p, nil
+		}
+		p.Operator = "@profile"
+		p.Profile = profile
+		p.Name = name
+		return p, nil
+	}
+
+	if idx := strings.LastIndex(inner, "@"); idx > 0 && (inner[idx:] == "@Q" || inner[idx:] == "@E") {
+		p.Operator = inner[idx:]
+		p.Name = inner[:idx]
+		return p, nil
+	}
+
+	if strings.HasPrefix(inner, "!") && inner != "!" {
+		p.Indirect = true
+		inner = inner[1:]
+	}
+
+	re := paramParserRegex
+	m, _ := re.FindStringMatch(inner)
+	if m == nil {
+		p.Name = inner
+		return p, nil
+	}
+	p.Name = m.GroupByName("name").String()
+	p.Operator = m.GroupByName("op").String()
+	p.Operand = unescapeBraces(m.GroupByName("operand").String())
+	return p, nil
+}
+
+// unescapeBraces replaces a braced param's escaped \{ and \} sequences
+// with literal { and }. paramFinderPattern lets those sequences through
+// the brace matching unharmed so a literal brace inside an operand (e.g.
+// `${FOO:-\}bar}`) doesn't prematurely close the param or get mistaken
+// for the start of one level of nesting; this undoes the escaping once
+// the operand's true text is needed.
+func unescapeBraces(s string) string {
+	if !strings.Contains(s, `\{`) && !strings.Contains(s, `\}`) {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\{`, "{")
+	s = strings.ReplaceAll(s, `\}`, "}")
+	return s
+}
+
+// evalOperand resolves a default operand's nested params. It is a variable
+// (rather than a direct call to parseEmbeddedParams) so tests can observe
+// how many times an operand is actually evaluated, as opposed to served
+// from the operand cache.
+var evalOperand func(operand string, cfg *Config) (string, error)
+
+func init() {
+	evalOperand = func(operand string, cfg *Config) (string, error) {
+		return parseEmbeddedParams(operand, cfg)
+	}
+}
+
+// resolveCachedOperand evaluates operand via evalOperand at most once per
+// distinct operand text for the lifetime of cfg, so that the same
+// expensive default (e.g. repeated across several param ids) only runs
+// once -- unless a "="/":=" assignment mutates the environment in the
+// meantime, which invalidates the whole cache (see handleDefaults) since
+// any previously cached operand may have depended on the value that just
+// changed.
+func resolveCachedOperand(operand string, cfg *Config) (string, error) {
+	if cfg.operandCache == nil {
+		cfg.operandCache = make(map[string]string)
+	}
+	if v, ok := cfg.operandCache[operand]; ok {
+		return v, nil
+	}
+	v, err := evalOperand(operand, cfg)
+	if err != nil {
+		return "", err
+	}
+	cfg.operandCache[operand] = v
+	return v, nil
+}
+
+// handleDefaults resolves the bash-style default/assign/error operators that
+// follow a variable name in a braced param. It returns a *ParseError for the
+// `?`/`:?` operators instead of panicking, so callers embedding parry can
+// handle the failure themselves.
+// posixOperators are the `${VAR<op>...}` forms defined by POSIX itself;
+// everything else (substring, replace, case conversion, suffix/prefix
+// stripping) is a bash extension and is rejected when cfg.interpret is
+// "posix".
+var posixOperators = map[string]bool{
+	"-": true, ":-": true,
+	"+": true, ":+": true,
+	"?": true, ":?": true,
+	"=": true, ":=": true,
+}
+
+// nameSentinel is the special `-`/`:-` default operand recognized in place
+// of a literal default value: it expands to the variable's own name
+// instead of being evaluated as a nested template. Only the whole operand
+// matching this exactly triggers it -- `@name` embedded in a longer
+// default (e.g. "${MISSING:-x@name}") is left as ordinary text.
+const nameSentinel = "@name"
+
+func handleDefaults(name, operator, operand string, cfg *Config) (string, error) {
+	if cfg != nil && cfg.interpret == "posix" && !posixOperators[operator] {
+		return "", &ParseError{
+			Fragment: operator,
+			Position: -1,
+			Message:  "operator is a bash extension, not available under --interpret=posix",
+			Class:    ClassParse,
+		}
+	}
+	value, ok := cfg.lookupVar(name)
+	switch operator {
+	case "-":
+		if !ok {
+			if operand == nameSentinel {
+				return name, nil
+			}
+			return resolveCachedOperand(operand, cfg)
+		}
+		return value, nil
+	case ":-":
+		if !ok || value == "" {
+			if operand == nameSentinel {
+				return name, nil
+			}
+			return resolveCachedOperand(operand, cfg)
+		}
+		return value, nil
+	case "+":
+		if ok {
+			return resolveCachedOperand(operand, cfg)
+		}
+		return "", nil
+	case ":+":
+		if ok && value != "" {
+			return resolveCachedOperand(operand, cfg)
+		}
+		return "", nil
+	case "?":
+		if !ok {
+			msg := operand
+			if msg == "" {
+				msg = fmt.Sprintf("%s: parameter not set", name)
+			}
+			return "", &ParseError{Fragment: name, Position: -1, Message: msg, Class: ClassRequiredVariable}
+		}
+		return value, nil
+	case ":?":
+		if !ok || value == "" {
+			msg := operand
+			if msg == "" {
+				msg = fmt.Sprintf("%s: parameter null or not set", name)
+			}
+			return "", &ParseError{Fragment: name, Position: -1, Message: msg, Class: ClassRequiredVariable}
+		}
+		return value, nil
+	case "=", ":=":
+		if !ok || (operator == ":=" && value == "") {
+			resolved, err := resolveCachedOperand(operand, cfg)
+			if err != nil {
+				return "", err
+			}
+			if cfg.lookuper == nil {
+				os.Setenv(name, resolved)
+			} else {
+				// A custom Lookuper never touches process environment
+				// state (see lookupVar), so the assignment goes into
+				// cfg.assignedVars instead of os.Setenv -- otherwise a
+				// later reference to name would still resolve against
+				// the custom source and never see this default.
+				if cfg.assignedVars == nil {
+					cfg.assignedVars = make(map[string]string)
+				}
+				cfg.assignedVars[name] = resolved
+			}
+			// Any operand cached before this point may have been
+			// evaluated against the old value of name -- e.g. an earlier
+			// "${OTHER:-$name}" with name unset. Drop the whole cache
+			// rather than try to work out which entries actually
+			// referenced name, so nothing downstream ever sees a value
+			// that predates this assignment.
+			cfg.operandCache = nil
+			return resolved, nil
+		}
+		return value, nil
+	case "%", "%%":
+		if !ok {
+			return "", nil
+		}
+		glob, err := parseEmbeddedParams(operand, cfg)
+		if err != nil {
+			return "", err
+		}
+		return stripSuffix(value, glob, operator == "%%"), nil
+	case "/", "//":
+		if !ok {
+			return "", nil
+		}
+		oldPat, newPat := splitReplacePattern(operand)
+		anchor := ""
+		switch {
+		case strings.HasPrefix(oldPat, "#"):
+			anchor = "start"
+			oldPat = oldPat[1:]
+		case strings.HasPrefix(oldPat, "%"):
+			anchor = "end"
+			oldPat = oldPat[1:]
+		}
+		oldResolved, err := parseEmbeddedParams(oldPat, cfg)
+		if err != nil {
+			return "", err
+		}
+		newResolved, err := parseEmbeddedParams(newPat, cfg)
+		if err != nil {
+			return "", err
+		}
+		return replaceGlob(value, oldResolved, newResolved, operator == "//", anchor, cfg)
+	case ":":
+		return substringExpand(value, operand), nil
+	case "^", "^^", ",", ",,":
+		if !ok {
+			return "", nil
+		}
+		upper := operator == "^" || operator == "^^"
+		whole := operator == "^^" || operator == ",,"
+		return caseConvert(value, operand, whole, upper)
+	default:
+		return value, nil
+	}
+}
+
+// caseConvert implements bash's `^`/`^^`/`,`/`,,` case operators. When
+// pattern is non-empty, only runes matching it (as a regex character
+// class, e.g. "[a-f]") are converted; otherwise every eligible rune is.
+// Returns a *ParseError if pattern doesn't compile as a regex.
+func caseConvert(value, pattern string, whole, upper bool) (string, error) {
+	var re *regexp2.Regexp
+	if pattern != "" {
+		var err error
+		re, err = regexp2.Compile("^(?:"+pattern+")$", 0)
+		if err != nil {
+			return "", &ParseError{Fragment: pattern, Position: -1, Message: "invalid case-conversion pattern: " + err.Error(), Class: ClassParse}
+		}
+	}
+	matches := func(r rune) bool {
+		if re == nil {
+			return true
+		}
+		m, _ := re.FindStringMatch(string(r))
+		return m != nil
+	}
+	transform := func(r rune) rune {
+		if upper {
+			return unicode.ToUpper(r)
+		}
+		return unicode.ToLower(r)
+	}
+
+	runes := []rune(value)
+	if whole {
+		for i, r := range runes {
+			if matches(r) {
+				runes[i] = transform(r)
+			}
+		}
+		return string(runes), nil
+	}
+	if len(runes) > 0 && matches(runes[0]) {
+		runes[0] = transform(runes[0])
+	}
+	return string(runes), nil
+}
+
+// substringExpand implements `${VAR:offset:length}` rune-based slicing.
+// operand is the text after the colon, e.g. "2:4", "3", or " -2". A
+// negative offset counts from the end of value. Out-of-range offsets clamp
+// to an empty result rather than panicking.
+func substringExpand(value, operand string) string {
+	operand = strings.TrimSpace(operand)
+	offsetStr, lengthStr, hasLength := strings.Cut(operand, ":")
+
+	offset, err := strconv.Atoi(strings.TrimSpace(offsetStr))
+	if err != nil {
+		return ""
+	}
+
+	runes := []rune(value)
+	n := len(runes)
+	if offset < 0 {
+		offset += n
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > n {
+		return ""
+	}
+
+	end := n
+	if hasLength {
+		length, err := strconv.Atoi(strings.TrimSpace(lengthStr))
+		if err != nil {
+			return ""
+		}
+		end = offset + length
+	}
+	if end < offset {
+		return ""
+	}
+	if end > n {
+		end = n
+	}
+	return string(runes[offset:end])
+}
+
+// quoteForReuse implements `${VAR@Q}`: wraps value in single quotes so it
+// can be safely re-sourced as shell input, escaping any embedded single
+// quote with the `'\”` idiom tokenizeByQuotes already understands when
+// parsing a quoted segment.
+func quoteForReuse(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// splitReplacePattern splits a `${VAR/old/new}` operand on its first
+// unescaped `/`. If there is no second `/`, new is empty (a bare removal).
+func splitReplacePattern(operand string) (old, new string) {
+	idx := strings.IndexByte(operand, '/')
+	if idx < 0 {
+		return operand, ""
+	}
+	return operand[:idx], operand[idx+1:]
+}
+
+// replaceGlob replaces occurrences of old in value with new. When global is
+// true every occurrence is replaced (`//`), otherwise only the first (`/`).
+// anchor, when "start" or "end", restricts the match to the beginning or
+// end of value. Normally old is a shell-style glob; when cfg.substRegex is
+// set, old is instead a regex pattern and new may reference its capturing
+// groups with `\1`, `\2`, etc. (see --subst-regex).
+func replaceGlob(value, old, new string, global bool, anchor string, cfg *Config) (string, error) {
+	regexMode := cfg != nil && cfg.substRegex
+	pattern := old
+	if !regexMode {
+		pattern = globToRegex(old)
+	}
+	switch anchor {
+	case "start":
+		pattern = "^(?:" + pattern + ")"
+	case "end":
+		pattern = "(?:" + pattern + ")$"
+	}
+	re, err := regexp2.Compile(pattern, 0)
+	if err != nil {
+		return "", &ParseError{Fragment: old, Position: -1, Message: "invalid --subst-regex pattern: " + err.Error(), Class: ClassParse}
+	}
+	if regexMode {
+		new = backrefToDotnetGroup(new)
+	}
+	count := 1
+	if global {
+		count = -1
+	}
+	result, err := re.Replace(value, new, -1, count)
+	if err != nil {
+		return value, nil
+	}
+	return result, nil
+}
+
+// backrefToDotnetGroup rewrites sed/PCRE-style `\1`..`\9` backreferences in
+// a --subst-regex replacement into regexp2's `$1`..`$9` syntax, leaving an
+// escaped `\\1` (a literal backslash followed by a digit) alone.
+func backrefToDotnetGroup(repl string) string {
+	var b strings.Builder
+	runes := []rune(repl)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] >= '0' && runes[i+1] <= '9' {
+			b.WriteByte('$')
+			b.WriteRune(runes[i+1])
+			i++
+			continue
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String()
+}
+
+// globToRegex translates a shell-style glob (`*`/`?` wildcards, everything
+// else literal) into the equivalent regexp2 pattern fragment.
+func globToRegex(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			if strings.ContainsRune(`.+()|[]{}^$\`, r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// stripSuffix removes the trailing portion of value matched by glob,
+// anchored at the end. When longest is true the longest matching suffix is
+// removed (bash's `%%`); otherwise the shortest (bash's `%`).
+func stripSuffix(value, glob string, longest bool) string {
+	// A greedy rest backtracks from the end, landing on the rightmost spot
+	// where the glob still matches -- i.e. the shortest possible suffix.
+	// A lazy rest expands from the start, landing on the leftmost spot --
+	// i.e. the longest possible suffix.
+	rest := ".*"
+	if longest {
+		rest = ".*?"
+	}
+	pattern := "^(?<rest>" + rest + ")(?:" + globToRegex(glob) + ")$"
+	re := regexp2.MustCompile(pattern, 0)
+	m, _ := re.FindStringMatch(value)
+	if m == nil {
+		return value
+	}
+	return m.GroupByName("rest").String()
+}
+
+// parseEmbeddedParams expands any params nested inside an operand string
+// (e.g. the default in "${FOO:-$BAR}") and returns the fully resolved text.
+func parseEmbeddedParams(s string, cfg *Config) (string, error) {
+	payload := []rune(s)
+	params, err := findParams(payload)
+	if err != nil {
+		return "", err
+	}
+	if len(params) == 0 {
+		return s, nil
+	}
+	values, err := mapParamValues(params, payload, cfg)
+	if err != nil {
+		return "", err
+	}
+	return reassemble(payload, params, values, cfg)
+}
+
+// strictExemptOperators are operators that already supply their own
+// fallback when the variable is unset -- a default, an assignment, the
+// `?`/`:?` error forms (which enforce their own distinct message), and `#`
+// (which reports a length of "0"). --strict's undefined-variable check
+// skips all of them.
+var strictExemptOperators = map[string]bool{
+	"-": true, ":-": true,
+	"+": true, ":+": true,
+	"=": true, ":=": true,
+	"?": true, ":?": true,
+	"#": true,
+}
+
+// checkStrictBraces returns a *ParseError for the first bare $VAR param
+// found, under --strict-braces, which requires every reference to use
+// ${VAR} form.
+func checkStrictBraces(params []Param) error {
+	for _, p := range params {
+		if !p.Braced {
+			return &ParseError{
+				Fragment: p.Raw,
+				Position: p.Position[0],
+				Message:  fmt.Sprintf("%s: bare $VAR is not allowed under --strict-braces, use ${%s}", p.Raw, p.Name),
+			}
+		}
+	}
+	return nil
+}
+
+// checkStrict returns a *ParseError listing every distinct variable name
+// referenced by params that resolves to unset, skipping indirection,
+// ${@prefix:...}, ${@profile:...} (it resolves against a named profile's
+// layer, not the active environment), and any operator in
+// strictExemptOperators since those already supply their own fallback.
+func checkStrict(params []Param, cfg *Config) error {
+	seen := make(map[string]bool)
+	var undefined []string
+	for _, p := range params {
+		if p.Indirect || p.Operator == "@prefix" || p.Operator == "@profile" || strictExemptOperators[p.Operator] || seen[p.Name] {
+			continue
+		}
+		if _, ok := cfg.lookupVar(p.Name); !ok {
+			seen[p.Name] = true
+			undefined = append(undefined, p.Name)
+		}
+	}
+	if len(undefined) == 0 {
+		return nil
+	}
+	return &ParseError{
+		Fragment: strings.Join(undefined, ", "),
+		Position: -1,
+		Message:  fmt.Sprintf("undefined variable(s) under --strict: %s", strings.Join(undefined, ", ")),
+		Class:    ClassRequiredVariable,
+	}
+}
+
+// checkRequiredParams returns a *ParseError listing every schema-declared
+// required variable (see --params-file) that's still unset once lookupVar
+// has consulted every lower-precedence source, including that same
+// schema's own Default entries. Unlike checkStrict, this walks the
+// schema's required names directly rather than the params found in the
+// template, since a required variable with no corresponding $VAR reference
+// is still required.
+func checkRequiredParams(cfg *Config) error {
+	names := make([]string, 0, len(cfg.requiredParams))
+	for name := range cfg.requiredParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var missing []string
+	for _, name := range names {
+		if _, ok := cfg.lookupVar(name); !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &ParseError{
+		Fragment: strings.Join(missing, ", "),
+		Position: -1,
+		Message:  fmt.Sprintf("required variable(s) missing, see --params-file: %s", strings.Join(missing, ", ")),
+		Class:    ClassRequiredVariable,
+	}
+}
+
+// checkErrorOperators resolves every distinct "?"/":?" param in params up
+// front and collects every failure into one *ParseError, instead of
+// leaving mapParamValues/reassemble to stop at the first one they happen to
+// reach -- for --collect-errors, so a CI run sees every required-but-
+// missing variable in one pass. A param that resolves fine is left for
+// mapParamValues/reassemble to resolve again normally; this only reports.
+func checkErrorOperators(params []Param, cfg *Config) error {
+	seen := make(map[string]bool)
+	var failures []string
+	for _, p := range params {
+		if (p.Operator != "?" && p.Operator != ":?") || seen[p.Id] {
+			continue
+		}
+		seen[p.Id] = true
+		if _, err := resolveParam(p, cfg); err != nil {
+			pe, ok := err.(*ParseError)
+			if !ok {
+				return err
+			}
+			failures = append(failures, fmt.Sprintf("%s (at offset %d)", pe.Message, p.Position[0]))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &ParseError{
+		Fragment: strings.Join(failures, "; "),
+		Position: -1,
+		Message:  fmt.Sprintf("%d required variable(s) failed under --collect-errors", len(failures)),
+		Class:    ClassRequiredVariable,
+	}
+}
+
+// lazyValue memoizes a single param's resolution (including any nested
+// default-operand evaluation it triggers), running it at most once and
+// only when get is actually called -- not when the value is built.
+type lazyValue struct {
+	resolve func() (string, error)
+	done    bool
+	value   string
+	err     error
+}
+
+// get runs resolve on first call and caches the result for every call
+// after.
+func (lv *lazyValue) get() (string, error) {
+	if !lv.done {
+		lv.value, lv.err = lv.resolve()
+		lv.done = true
+	}
+	return lv.value, lv.err
+}
+
+// mapParamValues builds a lazyValue for every distinct param (deduped by
+// Id), without resolving any of them yet -- resolution (including a
+// `${VAR:-$(expensive)}`-style default operand) only runs once something
+// calls lazyValue.get, which reassemble does exactly when it's about to
+// write that param's value into the output. payload is the template text
+// params was found in, needed only to locate each param's column for
+// --quote-for=yaml's indentation-sensitive block scalars; it may be nil
+// when quoteFor isn't "yaml".
+func mapParamValues(params []Param, payload []rune, cfg *Config) (map[string]*lazyValue, error) {
+	if cfg != nil && cfg.strictBraces {
+		if err := checkStrictBraces(params); err != nil {
+			return nil, err
+		}
+	}
+	if cfg != nil && cfg.strict {
+		if err := checkStrict(params, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if cfg != nil && len(cfg.requiredParams) > 0 {
+		if err := checkRequiredParams(cfg); err != nil {
+			return nil, err
+		}
+	}
+	if cfg != nil && cfg.collectErrors {
+		if err := checkErrorOperators(params, cfg); err != nil {
+			return nil, err
+		}
+	}
+	var yamlNewlines []int
+	if cfg != nil && cfg.quoteFor == "yaml" && payload != nil {
+		yamlNewlines = newlineOffsets(payload)
+	}
+	values := make(map[string]*lazyValue, len(params))
+	for _, p := range params {
+		if _, done := values[p.Id]; done {
+			continue
+		}
+		p := p
+		values[p.Id] = &lazyValue{resolve: func() (string, error) {
+			if cfg != nil && paramOutOfScope(p, cfg) {
+				// Out of --prefix/--only/--except's scope: leave the param
+				// exactly as written, skipping every later transform
+				// (--validate, --max-value-length, --number-locale,
+				// --quote-for) too, since this is literal passthrough
+				// text, not a resolved value.
+				return p.Raw, nil
+			}
+			value, err := resolveParam(p, cfg)
+			if err != nil {
+				return "", err
+			}
+			if cfg != nil && cfg.valueTransform != nil {
+				value, err = cfg.valueTransform(p.Name, value)
+				if err != nil {
+					return "", &ParseError{Fragment: p.Raw, Position: p.Position[0], Message: err.Error(), Class: ClassParse}
+				}
+			}
+			if cfg != nil && cfg.maxValueLength > 0 {
+				value, err = enforceMaxValueLength(value, p, cfg)
+				if err != nil {
+					return "", err
+				}
+			}
+			if cfg != nil && cfg.validatePatterns != nil {
+				if err := validateParamValue(value, p, cfg); err != nil {
+					return "", err
+				}
+			}
+			if cfg != nil && cfg.numberLocale != "" {
+				value = formatNumberLocale(value, cfg.numberLocale)
+			}
+			if cfg != nil && cfg.quoteFor != "" && p.Operator != "#" && p.Operator != "@prefix" {
+				_, column := lineAndColumn(yamlNewlines, p.Position[0])
+				value = quoteForValue(value, cfg.quoteFor, column)
+			}
+			return value, nil
+		}}
+	}
+	return values, nil
+}
+
+// enforceMaxValueLength applies --max-value-length to a single resolved
+// value, measured in runes. Under the default "truncate" policy it cuts
+// the value to the limit, appending cfg.truncationMarker if one was set;
+// under "error" it instead fails with the param's position, so an
+// unexpectedly huge value (a runaway env var, say) can't silently bloat
+// the rendered output.
+func enforceMaxValueLength(value string, p Param, cfg *Config) (string, error) {
+	runes := []rune(value)
+	if len(runes) <= cfg.maxValueLength {
+		return value, nil
+	}
+	if cfg.maxValueLengthPolicy == "error" {
+		return "", &ParseError{
+			Fragment: p.Raw,
+			Position: p.Position[0],
+			Message:  fmt.Sprintf("%s: resolved value is %d runes, exceeds --max-value-length=%d", p.Name, len(runes), cfg.maxValueLength),
+			Class:    ClassParse,
+		}
+	}
+	return string(runes[:cfg.maxValueLength]) + cfg.truncationMarker, nil
+}
+
+// validateParamValue applies --validate to a single resolved value: if
+// cfg.validatePatterns has an entry for p.Name, value must match it or
+// resolution fails with the variable name and the failing value, regardless
+// of whether that value came from a real setting or from falling through to
+// unset.
+func validateParamValue(value string, p Param, cfg *Config) error {
+	re, ok := cfg.validatePatterns[p.Name]
+	if !ok {
+		return nil
+	}
+	m, err := re.FindStringMatch(value)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return &ParseError{
+			Fragment: p.Raw,
+			Position: p.Position[0],
+			Message:  fmt.Sprintf("%s: value %q does not match --validate pattern", p.Name, value),
+			Class:    ClassParse,
+		}
+	}
+	return nil
+}
+
+// paramOutOfScope reports whether --prefix, --only, and/or --except mean p
+// should be left as literal text instead of resolved: p.Name doesn't start
+// with --prefix's prefix, --only is set and doesn't list p.Name, or
+// --except lists p.Name.
+func paramOutOfScope(p Param, cfg *Config) bool {
+	if cfg.prefix != "" && !strings.HasPrefix(p.Name, cfg.prefix) {
+		return true
+	}
+	if len(cfg.only) > 0 && !slices.Contains(cfg.only, p.Name) {
+		return true
+	}
+	return slices.Contains(cfg.except, p.Name)
+}
+
+// resolveParam computes the final string value for a single param.
+func resolveParam(p Param, cfg *Config) (string, error) {
+	if cfg != nil && cfg.interpret == "posix" && (p.Indirect || p.Operator == "@prefix" || p.Operator == "@profile") {
+		return "", &ParseError{
+			Fragment: p.Raw,
+			Position: p.Position[0],
+			Message:  "construct is a bash/parry extension, not available under --interpret=posix",
+			Class:    ClassParse,
+		}
+	}
+	if p.Indirect {
+		return resolveIndirect(p, cfg)
+	}
+	if !p.Braced || p.Operator == "" {
+		value, ok := cfg.lookupVar(p.Name)
+		if !ok && cfg != nil && cfg.onUnresolved != nil {
+			if hookValue, hookOK := cfg.onUnresolved(p.Name); hookOK {
+				value, ok = hookValue, true
+			}
+		}
+		if !ok && cfg != nil && cfg.placeholder {
+			return "<" + p.Name + ">", nil
+		}
+		if !ok && cfg != nil && cfg.preserve {
+			return p.Raw, nil
+		}
+		if cfg != nil && cfg.failOnEmpty && ok && value == "" {
+			return "", &ParseError{Fragment: p.Name, Position: p.Position[0], Message: "parameter is set but empty", Class: ClassParse}
+		}
+		return value, nil
+	}
+	if p.Operator == "#" {
+		value, ok := cfg.lookupVar(p.Name)
+		if !ok {
+			return "0", nil
+		}
+		return strconv.Itoa(len([]rune(value))), nil
+	}
+	if p.Operator == "@prefix" {
+		return resolvePrefixMatch(p.Operand), nil
+	}
+	if p.Operator == "@profile" {
+		value, _ := cfg.lookupProfileVar(p.Profile, p.Name)
+		return value, nil
+	}
+	if p.Operator == "@Q" {
+		value, _ := cfg.lookupVar(p.Name)
+		return quoteForReuse(value), nil
+	}
+	if p.Operator == "@E" {
+		value, _ := cfg.lookupVar(p.Name)
+		mode := ""
+		if cfg != nil {
+			mode = cfg.escapeMode
+		}
+		escaped, err := escapeWithMode(value, mode)
+		if err != nil {
+			return "", &ParseError{Fragment: p.Raw, Position: p.Position[0], Message: err.Error(), Class: ClassParse}
+		}
+		return escaped, nil
+	}
+	return handleDefaults(p.Name, p.Operator, p.Operand, cfg)
+}
+
+var identifierRegex = regexp2.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`, 0)
+
+func isValidIdentifier(s string) bool {
+	m, _ := identifierRegex.MatchString(s)
+	return m
+}
+
+// maxIndirectionDepth bounds how many successive ${!VAR} hops
+// chaseIndirection follows through live variable values before giving up.
+// This is a separate, dedicated limit from the nested-default recursion
+// parseEmbeddedParams re-enters through evalOperand, which is bounded
+// naturally by how deeply the template text itself nests braces --
+// indirection instead chases live environment state, where two variables
+// pointing at each other (A=B, B=A) would otherwise recurse forever.
+const maxIndirectionDepth = 32
+
+// resolveIndirect resolves `${!NAME...}`: NAME is looked up first to get an
+// intermediate variable name, which is then chased through chaseIndirection
+// in case it in turn names another variable, before being dereferenced
+// (composing with any default operator that followed the `!NAME`).
+func resolveIndirect(p Param, cfg *Config) (string, error) {
+	intermediate, _ := cfg.lookupVar(p.Name)
+	if !isValidIdentifier(intermediate) {
+		return "", nil
+	}
+	name, err := chaseIndirection(intermediate, cfg, p)
+	if err != nil {
+		return "", err
+	}
+	if p.Operator == "" {
+		value, _ := cfg.lookupVar(name)
+		return value, nil
+	}
+	return handleDefaults(name, p.Operator, p.Operand, cfg)
+}
+
+// chaseIndirection follows a chain of indirect references: while name's own
+// value is itself a valid identifier (e.g. REF1 -> "REF2" -> "REF3" ...),
+// that becomes the next name to look up, stopping as soon as a name is
+// unset or its value isn't a valid identifier, and erroring if the chain
+// runs past maxIndirectionDepth hops first.
+func chaseIndirection(name string, cfg *Config, p Param) (string, error) {
+	for depth := 0; ; depth++ {
+		value, ok := cfg.lookupVar(name)
+		if !ok || !isValidIdentifier(value) {
+			return name, nil
+		}
+		if depth >= maxIndirectionDepth {
+			return "", &ParseError{
+				Fragment: p.Raw,
+				Position: p.Position[0],
+				Message:  fmt.Sprintf("indirection too deep: %s exceeded %d levels", p.Raw, maxIndirectionDepth),
+				Class:    ClassParse,
+			}
+		}
+		name = value
+	}
+}
+
+// reassemble walks payload replacing each param's span with its resolved
+// value, leaving everything else untouched. It resolves each distinct
+// lazyValue the first time it's actually needed for output, so a param
+// that ends up not being written never runs its resolution. params was
+// already found against the original payload, so a resolved value is
+// spliced in verbatim and never re-scanned for further param syntax -- a
+// value containing "${OTHER}", "}}", or ")" survives intact.
+//
+// When cfg.mergeAdjacentPlaceholders is set, a run of two or more params
+// that are textually adjacent (nothing between their spans) and all
+// resolved to a --placeholder marker is collapsed into a single combined
+// marker, e.g. "${A}${B}" with both unset becomes "<A,B>" instead of
+// "<A><B>".
+func reassemble(payload []rune, params []Param, values map[string]*lazyValue, cfg *Config) (string, error) {
+	spans, err := ansiCSpans(payload)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	cursor := 0
+	i := 0
+	for i < len(params) {
+		p := params[i]
+		value, err := values[p.Id].get()
+		if err != nil {
+			return "", err
+		}
+		end := p.Position[1]
+
+		if cfg != nil && cfg.mergeAdjacentPlaceholders && isPlaceholderValue(value) {
+			names := []string{placeholderName(value)}
+			j := i + 1
+			for j < len(params) && params[j].Position[0] == params[j-1].Position[1] {
+				next, err := values[params[j].Id].get()
+				if err != nil {
+					return "", err
+				}
+				if !isPlaceholderValue(next) {
+					break
+				}
+				names = append(names, placeholderName(next))
+				end = params[j].Position[1]
+				j++
+			}
+			if len(names) > 1 {
+				value = "<" + strings.Join(names, ",") + ">"
+				i = j
+				b.WriteString(renderLiteral(payload, cursor, p.Position[0], spans))
+				b.WriteString(value)
+				cursor = end
+				continue
+			}
+		}
+
+		b.WriteString(renderLiteral(payload, cursor, p.Position[0], spans))
+		b.WriteString(value)
+		cursor = end
+		i++
+	}
+	b.WriteString(renderLiteral(payload, cursor, len(payload), spans))
+	return b.String(), nil
+}
+
+// isPlaceholderValue reports whether value is a --placeholder marker, i.e.
+// "<" + name + ">".
+func isPlaceholderValue(value string) bool {
+	return len(value) >= 2 && value[0] == '<' && value[len(value)-1] == '>'
+}
+
+// placeholderName strips the surrounding "<"/">" from a --placeholder
+// marker, returning the bare name inside.
+func placeholderName(value string) string {
+	return value[1 : len(value)-1]
+}
@@ -0,0 +1,663 @@
+package parry
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func mustExpand(t *testing.T, tmpl string, cfg *Config) string {
+	t.Helper()
+	got, err := parseEmbeddedParams(tmpl, cfg)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", tmpl, err)
+	}
+	return got
+}
+
+func TestSuffixRemoval(t *testing.T) {
+	os.Setenv("FOO", "archive.tar.gz")
+	defer os.Unsetenv("FOO")
+
+	cases := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"shortest", "${FOO%.*}", "archive.tar"},
+		{"longest", "${FOO%%.*}", "archive"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := mustExpand(t, c.tmpl, NewConfig())
+			if got != c.want {
+				t.Fatalf("%s: got %q, want %q", c.tmpl, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSuffixRemovalWithNestedExpansion(t *testing.T) {
+	os.Setenv("FILE", "report.txt")
+	os.Setenv("EXT", "txt")
+	defer os.Unsetenv("FILE")
+	defer os.Unsetenv("EXT")
+
+	got := mustExpand(t, "${FILE%.${EXT}}", NewConfig())
+	if got != "report" {
+		t.Fatalf("got %q, want %q", got, "report")
+	}
+}
+
+func TestSuffixRemovalUnsetVariable(t *testing.T) {
+	os.Unsetenv("MISSING_VAR")
+	got := mustExpand(t, "${MISSING_VAR%.foo}", NewConfig())
+	if got != "" {
+		t.Fatalf("got %q, want empty string", got)
+	}
+}
+
+func TestSubstringReplacement(t *testing.T) {
+	cases := []struct {
+		name string
+		env  string
+		tmpl string
+		want string
+	}{
+		{"single", "a.b.c", "${HOST/./_}", "a_b.c"},
+		{"global", "a.b.c", "${HOST//./_}", "a_b_c"},
+		{"anchored-start", "aabbaa", "${HOST/#a/X}", "Xabbaa"},
+		{"anchored-end", "aabbaa", "${HOST/%a/X}", "aabbaX"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			os.Setenv("HOST", c.env)
+			defer os.Unsetenv("HOST")
+			got := mustExpand(t, c.tmpl, NewConfig())
+			if got != c.want {
+				t.Fatalf("%s: got %q, want %q", c.tmpl, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSubstRegexBackreferenceSwap(t *testing.T) {
+	os.Setenv("DATE", "2024-03")
+	defer os.Unsetenv("DATE")
+
+	cfg := NewConfig()
+	cfg.SetSubstRegex(true)
+	got := mustExpand(t, `${DATE/(\d+)-(\d+)/\2-\1}`, cfg)
+	if got != "03-2024" {
+		t.Fatalf("got %q, want %q", got, "03-2024")
+	}
+}
+
+func TestSubstRegexInvalidPatternErrors(t *testing.T) {
+	os.Setenv("DATE", "2024-03")
+	defer os.Unsetenv("DATE")
+
+	cfg := NewConfig()
+	cfg.SetSubstRegex(true)
+	_, err := mustExpandErr(t, `${DATE/(\d+/x}`, cfg)
+	if err == nil {
+		t.Fatal("expected an error for an invalid regex pattern")
+	}
+}
+
+func TestNameSentinelDefaultExpandsToVariablesOwnName(t *testing.T) {
+	os.Unsetenv("MISSING")
+	defer os.Unsetenv("MISSING")
+
+	got := mustExpand(t, "${MISSING:-@name}", NewConfig())
+	if got != "MISSING" {
+		t.Fatalf("got %q, want %q", got, "MISSING")
+	}
+
+	// @name embedded in a longer default, or appearing outside a default
+	// operand altogether, is ordinary text.
+	got = mustExpand(t, "${MISSING:-x@name}", NewConfig())
+	if got != "x@name" {
+		t.Fatalf("got %q, want %q", got, "x@name")
+	}
+	got = mustExpand(t, "literal @name text", NewConfig())
+	if got != "literal @name text" {
+		t.Fatalf("got %q, want %q", got, "literal @name text")
+	}
+}
+
+func TestAssignDefaultOperator(t *testing.T) {
+	os.Unsetenv("PORT")
+	defer os.Unsetenv("PORT")
+
+	got := mustExpand(t, "${PORT:=8080}", NewConfig())
+	if got != "8080" {
+		t.Fatalf("got %q, want %q", got, "8080")
+	}
+	if env := os.Getenv("PORT"); env != "8080" {
+		t.Fatalf("expected PORT to be set in the environment, got %q", env)
+	}
+
+	// A later reference in the same run now sees the assigned value.
+	got = mustExpand(t, "$PORT", NewConfig())
+	if got != "8080" {
+		t.Fatalf("got %q, want %q", got, "8080")
+	}
+}
+
+func TestAssignDefaultOperatorWithCustomLookuperDoesNotTouchProcessEnvironment(t *testing.T) {
+	os.Unsetenv("ASSIGN_LOOKUPER_PORT")
+	defer os.Unsetenv("ASSIGN_LOOKUPER_PORT")
+
+	cfg := NewConfig()
+	cfg.SetLookuper(mapLookuper{})
+
+	got := mustExpand(t, "${ASSIGN_LOOKUPER_PORT:=8080}", cfg)
+	if got != "8080" {
+		t.Fatalf("got %q, want %q", got, "8080")
+	}
+	if env := os.Getenv("ASSIGN_LOOKUPER_PORT"); env != "" {
+		t.Fatalf("expected ASSIGN_LOOKUPER_PORT to stay unset in the process environment, got %q", env)
+	}
+
+	// A later reference against the same cfg sees the assignment, even
+	// though the custom Lookuper itself was never told about it.
+	got = mustExpand(t, "$ASSIGN_LOOKUPER_PORT", cfg)
+	if got != "8080" {
+		t.Fatalf("got %q, want %q", got, "8080")
+	}
+}
+
+func TestAssignDefaultOperatorWithNestedOperand(t *testing.T) {
+	os.Unsetenv("HOST")
+	os.Setenv("DEFAULT_HOST", "localhost")
+	defer os.Unsetenv("HOST")
+	defer os.Unsetenv("DEFAULT_HOST")
+
+	got := mustExpand(t, "${HOST:=$DEFAULT_HOST}", NewConfig())
+	if got != "localhost" {
+		t.Fatalf("got %q, want %q", got, "localhost")
+	}
+}
+
+func TestFailOnEmpty(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetFailOnEmpty(true)
+
+	os.Setenv("FOO", "")
+	defer os.Unsetenv("FOO")
+
+	if _, err := parseEmbeddedParams("$FOO", cfg); err == nil {
+		t.Fatal("expected an error for a set-but-empty variable")
+	}
+
+	os.Setenv("FOO", "x")
+	got := mustExpand(t, "$FOO", cfg)
+	if got != "x" {
+		t.Fatalf("got %q, want %q", got, "x")
+	}
+}
+
+func TestCaseConversionOperators(t *testing.T) {
+	cases := []struct {
+		name string
+		env  string
+		tmpl string
+		want string
+	}{
+		{"upper-first", "hello world", "${S^}", "Hello world"},
+		{"upper-all", "hello world", "${S^^}", "HELLO WORLD"},
+		{"lower-first", "HELLO WORLD", "${S,}", "hELLO WORLD"},
+		{"lower-all", "HELLO WORLD", "${S,,}", "hello world"},
+		{"multibyte-upper-all", "ö test", "${S^^}", "Ö TEST"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			os.Setenv("S", c.env)
+			defer os.Unsetenv("S")
+			got := mustExpand(t, c.tmpl, NewConfig())
+			if got != c.want {
+				t.Fatalf("%s: got %q, want %q", c.tmpl, got, c.want)
+			}
+		})
+	}
+}
+
+func TestCaseConversionWithSelectivePattern(t *testing.T) {
+	cases := []struct {
+		name string
+		env  string
+		tmpl string
+		want string
+	}{
+		{"upper-vowels-all", "hello world", "${S^^[aeiou]}", "hEllO wOrld"},
+		{"lower-vowels-all", "HELLO WORLD", "${S,,[AEIOU]}", "HeLLo WoRLD"},
+		{"upper-vowels-first-only", "ooze", "${S^[aeiou]}", "Ooze"},
+		{"pattern-excludes-non-matching-first-char", "xylophone", "${S^[aeiou]}", "xylophone"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			os.Setenv("S", c.env)
+			defer os.Unsetenv("S")
+			got := mustExpand(t, c.tmpl, NewConfig())
+			if got != c.want {
+				t.Fatalf("%s: got %q, want %q", c.tmpl, got, c.want)
+			}
+		})
+	}
+}
+
+func TestCaseConversionInvalidPatternErrorsInsteadOfPanicking(t *testing.T) {
+	os.Setenv("S", "hello")
+	defer os.Unsetenv("S")
+
+	_, err := mustExpandErr(t, "${S^^[a-}", NewConfig())
+	if err == nil {
+		t.Fatal("expected an error for an invalid case-conversion pattern")
+	}
+}
+
+func TestLengthOperator(t *testing.T) {
+	os.Setenv("NAME", "hello")
+	defer os.Unsetenv("NAME")
+	os.Unsetenv("MISSING_LEN")
+
+	if got := mustExpand(t, "${#NAME}", NewConfig()); got != "5" {
+		t.Fatalf("got %q, want %q", got, "5")
+	}
+	if got := mustExpand(t, "${#MISSING_LEN}", NewConfig()); got != "0" {
+		t.Fatalf("got %q, want %q", got, "0")
+	}
+}
+
+func TestQuoteForReuseTransform(t *testing.T) {
+	os.Setenv("MSG", "it's a test")
+	defer os.Unsetenv("MSG")
+
+	got := mustExpand(t, "${MSG@Q}", NewConfig())
+	want := `'it'\''s a test'`
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestEscapeInterpretTransform(t *testing.T) {
+	os.Setenv("RAW", `line1\nline2`)
+	defer os.Unsetenv("RAW")
+
+	got := mustExpand(t, "${RAW@E}", NewConfig())
+	want := "line1\nline2"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestEscapeModeSelectsGrammar(t *testing.T) {
+	os.Setenv("RAW", `line1\nline2 \u00F6`)
+	defer os.Unsetenv("RAW")
+
+	cases := []struct {
+		mode string
+		want string
+	}{
+		{"", "line1\nline2 \\u00F6"},
+		{"c", "line1\nline2 \\u00F6"},
+		{"json", "line1\nline2 ö"},
+		{"none", `line1\nline2 \u00F6`},
+	}
+	for _, c := range cases {
+		t.Run(c.mode, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.SetEscapeMode(c.mode)
+			got := mustExpand(t, "${RAW@E}", cfg)
+			if got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestEscapeModeInvalidRejectedByValidate(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFile("/nonexistent-does-not-matter")
+	cfg.SetEscapeMode("xml")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown escape-mode")
+	}
+}
+
+func TestSubstringExpansion(t *testing.T) {
+	os.Setenv("VERSION", "1.2.3-beta")
+	defer os.Unsetenv("VERSION")
+
+	cases := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"offset-and-length", "${VERSION:0:3}", "1.2"},
+		{"offset-only", "${VERSION:6}", "beta"},
+		{"negative-offset", "${VERSION: -4}", "beta"},
+		{"out-of-range", "${VERSION:100}", ""},
+		{"out-of-range-negative", "${VERSION: -100}", "1.2.3-beta"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := mustExpand(t, c.tmpl, NewConfig())
+			if got != c.want {
+				t.Fatalf("%s: got %q, want %q", c.tmpl, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSubstringExpansionDoesNotShadowDefaultOperator(t *testing.T) {
+	os.Unsetenv("MISSING_SUB")
+	got := mustExpand(t, "${MISSING_SUB:-2}", NewConfig())
+	if got != "2" {
+		t.Fatalf("got %q, want %q", got, "2")
+	}
+}
+
+func TestDefaultOperandEvaluatesOncePerDistinctId(t *testing.T) {
+	os.Unsetenv("A")
+	os.Unsetenv("B")
+	os.Setenv("COUNTER", "expensive")
+	defer os.Unsetenv("COUNTER")
+
+	var calls int
+	orig := evalOperand
+	evalOperand = func(operand string, cfg *Config) (string, error) {
+		calls++
+		return orig(operand, cfg)
+	}
+	defer func() { evalOperand = orig }()
+
+	cfg := NewConfig()
+	got := mustExpand(t, "${A:-$COUNTER}${A:-$COUNTER}${B:-$COUNTER}", cfg)
+
+	want := "expensiveexpensiveexpensive"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	// "${A:-$COUNTER}" appears twice (same id, deduped by mapParamValues) and
+	// "${B:-$COUNTER}" once (a different id but an identical operand, so it
+	// shares the operand cache) -- the expensive operand only runs once.
+	if calls != 1 {
+		t.Fatalf("expected operand to evaluate once, got %d calls", calls)
+	}
+}
+
+func TestOperandCacheInvalidatesAfterAssignmentMutatesEnv(t *testing.T) {
+	os.Unsetenv("CACHE_INVALIDATE_VAR")
+	os.Unsetenv("CACHE_INVALIDATE_X")
+	os.Unsetenv("CACHE_INVALIDATE_Y")
+	defer os.Unsetenv("CACHE_INVALIDATE_VAR")
+
+	var calls int
+	orig := evalOperand
+	evalOperand = func(operand string, cfg *Config) (string, error) {
+		calls++
+		return orig(operand, cfg)
+	}
+	defer func() { evalOperand = orig }()
+
+	cfg := NewConfig()
+	tmpl := "[${CACHE_INVALIDATE_X:-$CACHE_INVALIDATE_VAR}]" +
+		"[${CACHE_INVALIDATE_VAR:=5}]" +
+		"[${CACHE_INVALIDATE_Y:-$CACHE_INVALIDATE_VAR}]"
+	got := mustExpand(t, tmpl, cfg)
+
+	// CACHE_INVALIDATE_X's operand ("$CACHE_INVALIDATE_VAR") is cached
+	// while the var is still unset and resolves to "". The := assignment
+	// that follows sets it to "5" and must invalidate that cache entry --
+	// otherwise CACHE_INVALIDATE_Y's identical operand text would wrongly
+	// replay the stale "" instead of re-reading the now-set value.
+	want := "[][5][5]"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	// 3 calls: "$CACHE_INVALIDATE_VAR" (X, before the assignment), "5"
+	// (the assignment's own operand), and "$CACHE_INVALIDATE_VAR" again
+	// (Y, after invalidation -- if the cache had wrongly survived, this
+	// would be served from it and calls would stay at 2).
+	if calls != 3 {
+		t.Fatalf("expected 3 operand evaluations, got %d calls", calls)
+	}
+}
+
+func TestLazyValueSkipsResolutionForParamOmittedFromReassemble(t *testing.T) {
+	os.Unsetenv("KEPT")
+	os.Unsetenv("DROPPED")
+	os.Setenv("COUNTER", "expensive")
+	defer os.Unsetenv("COUNTER")
+
+	var calls int
+	orig := evalOperand
+	evalOperand = func(operand string, cfg *Config) (string, error) {
+		calls++
+		return orig(operand, cfg)
+	}
+	defer func() { evalOperand = orig }()
+
+	cfg := NewConfig()
+	payload := []rune("${KEPT:-$COUNTER}${DROPPED:-$COUNTER}")
+	params, err := findParams(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := mapParamValues(params, payload, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Fatalf("mapParamValues must not resolve anything eagerly, got %d calls", calls)
+	}
+
+	// Simulate a caller that decided, after building values, to only emit
+	// the first param -- the way a future filtering feature would. Only the
+	// emitted param's lazyValue should ever be forced.
+	if _, err := values[params[0].Id].get(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the emitted param's operand to evaluate once, got %d calls", calls)
+	}
+
+	// reassemble only substitutes the params it's given -- the raw text of
+	// the omitted one passes through untouched, as it would for any span
+	// outside the given params slice.
+	got, err := reassemble(payload, params[:1], values, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "expensive${DROPPED:-$COUNTER}"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the omitted param's operand to never evaluate, got %d calls", calls)
+	}
+}
+
+func TestSubstringReplacementWithNestedOperands(t *testing.T) {
+	os.Setenv("HOST", "example.com")
+	os.Setenv("SEP", ".")
+	os.Setenv("REPL", "_")
+	defer os.Unsetenv("HOST")
+	defer os.Unsetenv("SEP")
+	defer os.Unsetenv("REPL")
+
+	got := mustExpand(t, "${HOST//$SEP/$REPL}", NewConfig())
+	if got != "example_com" {
+		t.Fatalf("got %q, want %q", got, "example_com")
+	}
+}
+
+func TestIndirectExpansion(t *testing.T) {
+	os.Setenv("REF", "HOME")
+	os.Setenv("HOME", "/home/x")
+	defer os.Unsetenv("REF")
+	defer os.Unsetenv("HOME")
+
+	got := mustExpand(t, "${!REF}", NewConfig())
+	if got != "/home/x" {
+		t.Fatalf("got %q, want %q", got, "/home/x")
+	}
+}
+
+func TestIndirectExpansionComposesWithDefaultOperator(t *testing.T) {
+	os.Setenv("REF", "TARGET")
+	os.Unsetenv("TARGET")
+	defer os.Unsetenv("REF")
+
+	got := mustExpand(t, "${!REF:-fallback}", NewConfig())
+	if got != "fallback" {
+		t.Fatalf("got %q, want %q", got, "fallback")
+	}
+}
+
+func TestIndirectExpansionInvalidIdentifierIsEmpty(t *testing.T) {
+	os.Setenv("REF", "not a name!")
+	defer os.Unsetenv("REF")
+
+	got := mustExpand(t, "${!REF}", NewConfig())
+	if got != "" {
+		t.Fatalf("got %q, want empty string", got)
+	}
+}
+
+func TestIndirectExpansionChainsThroughMultipleHops(t *testing.T) {
+	os.Setenv("CHAIN_REF1", "CHAIN_REF2")
+	os.Setenv("CHAIN_REF2", "CHAIN_REF3")
+	os.Setenv("CHAIN_REF3", "final-value")
+	defer os.Unsetenv("CHAIN_REF1")
+	defer os.Unsetenv("CHAIN_REF2")
+	defer os.Unsetenv("CHAIN_REF3")
+
+	got := mustExpand(t, "${!CHAIN_REF1}", NewConfig())
+	if got != "final-value" {
+		t.Fatalf("got %q, want %q", got, "final-value")
+	}
+}
+
+func TestIndirectExpansionChainAtDepthLimitSucceeds(t *testing.T) {
+	names := setIndirectionChain(t, maxIndirectionDepth)
+	got := mustExpand(t, "${!"+names[0]+"}", NewConfig())
+	if got != "final-value" {
+		t.Fatalf("got %q, want %q", got, "final-value")
+	}
+}
+
+func TestIndirectExpansionChainBeyondDepthLimitErrors(t *testing.T) {
+	names := setIndirectionChain(t, maxIndirectionDepth*3)
+	_, err := parseEmbeddedParams("${!"+names[0]+"}", NewConfig())
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Message == "" || !strings.Contains(pe.Message, "indirection too deep") {
+		t.Fatalf("got message %q, want it to mention %q", pe.Message, "indirection too deep")
+	}
+}
+
+// setIndirectionChain sets up hops successive env vars CHAIN_DEPTH_0,
+// CHAIN_DEPTH_1, ..., each naming the next, with the last one holding
+// "final-value" instead of another name, and returns their names in order.
+func setIndirectionChain(t *testing.T, hops int) []string {
+	t.Helper()
+	names := make([]string, hops+1)
+	for i := range names {
+		names[i] = fmt.Sprintf("CHAIN_DEPTH_%d", i)
+	}
+	for i, name := range names {
+		if i == len(names)-1 {
+			t.Setenv(name, "final-value")
+			continue
+		}
+		t.Setenv(name, names[i+1])
+	}
+	return names
+}
+
+func TestErrorOperatorReturnsParseError(t *testing.T) {
+	os.Unsetenv("MISSING_REQUIRED")
+
+	_, err := parseEmbeddedParams("${MISSING_REQUIRED:?must be set}", NewConfig())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var parseErr *ParseError
+	if pe, ok := err.(*ParseError); ok {
+		parseErr = pe
+	} else {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+	if parseErr.Message != "must be set" {
+		t.Fatalf("got message %q, want %q", parseErr.Message, "must be set")
+	}
+}
+
+func TestErrorOperatorDefaultMessage(t *testing.T) {
+	os.Unsetenv("MISSING_REQUIRED")
+
+	_, err := parseEmbeddedParams("${MISSING_REQUIRED?}", NewConfig())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+// TestResolvedValueIsInsertedVerbatimWithoutRescanning guards the
+// correctness guarantee reassemble relies on: params are found once against
+// the original payload, and each resolved value is spliced into the output
+// as an opaque string, never re-tokenized for further param syntax -- so a
+// value that happens to contain "${...}", "$OTHER", "}}", or ")" survives
+// intact instead of being mistaken for another param or a closing delimiter.
+func TestResolvedValueIsInsertedVerbatimWithoutRescanning(t *testing.T) {
+	os.Setenv("OTHER", "should-not-appear")
+	defer os.Unsetenv("OTHER")
+
+	cases := []struct {
+		name  string
+		value string
+	}{
+		{"dollar-brace", "a${OTHER}b"},
+		{"bare-dollar", "a$OTHERb"},
+		{"double-close-brace", "a}}b"},
+		{"close-paren", "a)b"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			os.Setenv("FOO", c.value)
+			defer os.Unsetenv("FOO")
+
+			got := mustExpand(t, "${FOO}", NewConfig())
+			if got != c.value {
+				t.Fatalf("got %q, want %q", got, c.value)
+			}
+		})
+	}
+}
+
+// BenchmarkFindParams exercises findParams/parseParam over a template with
+// many distinct braced params (each triggering paramParserRegex), showing
+// the payoff of compiling paramFinderRegex/paramParserRegex once at package
+// init instead of per call.
+func BenchmarkFindParams(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 2000; i++ {
+		sb.WriteString("Lorem ipsum ${VAR")
+		sb.WriteString(string(rune('A' + i%26)))
+		sb.WriteString(":-default} dolor $OTHER sit amet. ")
+	}
+	payload := []rune(sb.String())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := findParams(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
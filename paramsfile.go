@@ -0,0 +1,43 @@
+package parry
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParamSchema describes one variable's expected shape in a --params-file
+// document: a human-readable description (informational only), whether
+// resolution must fail if it ends up unset (see checkRequiredParams), a
+// value to fall back to when it isn't set anywhere else (see
+// Config.paramsFileDefault), and a regex its resolved value must match,
+// folded into Config.validatePatterns alongside any --validate flags.
+type ParamSchema struct {
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	Default     string `json:"default,omitempty" yaml:"default,omitempty"`
+	Validate    string `json:"validate,omitempty" yaml:"validate,omitempty"`
+}
+
+// parseParamsFile reads path as a flat object mapping each variable name
+// to its ParamSchema, the same flat-object shape --kvjson uses for its
+// value map. YAML is used when path ends in .yaml or .yml; JSON otherwise.
+func parseParamsFile(path string) (map[string]ParamSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, wrapFileError(path, err)
+	}
+	schema := make(map[string]ParamSchema)
+	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+		if err := yaml.Unmarshal(data, &schema); err != nil {
+			return nil, &ParseError{Fragment: path, Position: -1, Message: "invalid --params-file: " + err.Error()}
+		}
+		return schema, nil
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, &ParseError{Fragment: path, Position: -1, Message: "invalid --params-file: " + err.Error()}
+	}
+	return schema, nil
+}
@@ -0,0 +1,117 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeParamsFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParamsFileRequiredVariableMissingFails(t *testing.T) {
+	os.Unsetenv("PARAMS_FILE_REQUIRED")
+	path := writeParamsFile(t, "schema.json", `{
+		"PARAMS_FILE_REQUIRED": {"required": true, "description": "must be set"}
+	}`)
+
+	cfg := NewConfig()
+	cfg.SetParamsFile(path)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := mustExpandErr(t, "v=$PARAMS_FILE_REQUIRED", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Fragment != "PARAMS_FILE_REQUIRED" {
+		t.Fatalf("got fragment %q, want %q", pe.Fragment, "PARAMS_FILE_REQUIRED")
+	}
+}
+
+func TestParamsFileRequiredVariablePresentPasses(t *testing.T) {
+	t.Setenv("PARAMS_FILE_REQUIRED_OK", "1")
+	path := writeParamsFile(t, "schema.json", `{
+		"PARAMS_FILE_REQUIRED_OK": {"required": true}
+	}`)
+
+	cfg := NewConfig()
+	cfg.SetParamsFile(path)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := mustExpandErr(t, "v=$PARAMS_FILE_REQUIRED_OK", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "v=1" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestParamsFileDefaultSatisfiesRequiredAndResolution(t *testing.T) {
+	os.Unsetenv("PARAMS_FILE_DEFAULTED")
+	path := writeParamsFile(t, "schema.json", `{
+		"PARAMS_FILE_DEFAULTED": {"required": true, "default": "fallback-value"}
+	}`)
+
+	cfg := NewConfig()
+	cfg.SetParamsFile(path)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := mustExpandErr(t, "v=$PARAMS_FILE_DEFAULTED", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "v=fallback-value" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestParamsFileValidatePatternAppliesToResolvedValue(t *testing.T) {
+	t.Setenv("PARAMS_FILE_PORT", "not-a-port")
+	path := writeParamsFile(t, "schema.json", `{
+		"PARAMS_FILE_PORT": {"validate": "^\\d+$"}
+	}`)
+
+	cfg := NewConfig()
+	cfg.SetParamsFile(path)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := mustExpandErr(t, "port=$PARAMS_FILE_PORT", cfg)
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+}
+
+func TestParamsFileYAMLExtensionIsParsedAsYAML(t *testing.T) {
+	os.Unsetenv("PARAMS_FILE_YAML_VAR")
+	path := writeParamsFile(t, "schema.yaml", "PARAMS_FILE_YAML_VAR:\n  default: from-yaml\n")
+
+	cfg := NewConfig()
+	cfg.SetParamsFile(path)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := mustExpandErr(t, "v=$PARAMS_FILE_YAML_VAR", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "v=from-yaml" {
+		t.Fatalf("got %q", got)
+	}
+}
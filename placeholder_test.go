@@ -0,0 +1,72 @@
+package parry
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPlaceholderMarksUnsetBareParam(t *testing.T) {
+	os.Unsetenv("PLACEHOLDER_UNSET")
+
+	cfg := NewConfig()
+	cfg.SetPlaceholder(true)
+
+	got := mustExpand(t, "${PLACEHOLDER_UNSET}", cfg)
+	if got != "<PLACEHOLDER_UNSET>" {
+		t.Fatalf("got %q, want %q", got, "<PLACEHOLDER_UNSET>")
+	}
+}
+
+func TestPlaceholderLeavesSetParamAlone(t *testing.T) {
+	os.Setenv("PLACEHOLDER_SET", "value")
+	defer os.Unsetenv("PLACEHOLDER_SET")
+
+	cfg := NewConfig()
+	cfg.SetPlaceholder(true)
+
+	got := mustExpand(t, "${PLACEHOLDER_SET}", cfg)
+	if got != "value" {
+		t.Fatalf("got %q, want %q", got, "value")
+	}
+}
+
+func TestMergeAdjacentPlaceholdersCombinesConsecutiveMarkers(t *testing.T) {
+	os.Unsetenv("PLACEHOLDER_A")
+	os.Unsetenv("PLACEHOLDER_B")
+
+	cfg := NewConfig()
+	cfg.SetPlaceholder(true)
+	cfg.SetMergeAdjacentPlaceholders(true)
+
+	got := mustExpand(t, "${PLACEHOLDER_A}${PLACEHOLDER_B}", cfg)
+	if got != "<PLACEHOLDER_A,PLACEHOLDER_B>" {
+		t.Fatalf("got %q, want %q", got, "<PLACEHOLDER_A,PLACEHOLDER_B>")
+	}
+}
+
+func TestMergeAdjacentPlaceholdersLeavesNonAdjacentMarkersSeparate(t *testing.T) {
+	os.Unsetenv("PLACEHOLDER_A")
+	os.Unsetenv("PLACEHOLDER_B")
+
+	cfg := NewConfig()
+	cfg.SetPlaceholder(true)
+	cfg.SetMergeAdjacentPlaceholders(true)
+
+	got := mustExpand(t, "${PLACEHOLDER_A} ${PLACEHOLDER_B}", cfg)
+	if got != "<PLACEHOLDER_A> <PLACEHOLDER_B>" {
+		t.Fatalf("got %q, want %q", got, "<PLACEHOLDER_A> <PLACEHOLDER_B>")
+	}
+}
+
+func TestMergeAdjacentPlaceholdersWithoutPlaceholderIsNoop(t *testing.T) {
+	os.Unsetenv("PLACEHOLDER_A")
+	os.Unsetenv("PLACEHOLDER_B")
+
+	cfg := NewConfig()
+	cfg.SetMergeAdjacentPlaceholders(true)
+
+	got := mustExpand(t, "${PLACEHOLDER_A}${PLACEHOLDER_B}", cfg)
+	if got != "" {
+		t.Fatalf("got %q, want empty string", got)
+	}
+}
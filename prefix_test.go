@@ -0,0 +1,39 @@
+package parry
+
+import "testing"
+
+func TestPrefixLeavesNonMatchingParamsLiteral(t *testing.T) {
+	t.Setenv("PARRY_NAME", "app")
+	t.Setenv("OTHER", "untouched")
+	cfg := NewConfig()
+	cfg.SetPrefix("PARRY_")
+
+	got := mustExpand(t, "a=$PARRY_NAME b=$OTHER", cfg)
+	want := "a=app b=$OTHER"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrefixPassesThroughUnsetNonMatchingParamUnchanged(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetPrefix("PARRY_")
+
+	got := mustExpand(t, "b=${OTHER:-fallback}", cfg)
+	want := "b=${OTHER:-fallback}"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWithoutPrefixEveryParamExpands(t *testing.T) {
+	t.Setenv("PARRY_NAME", "app")
+	t.Setenv("OTHER", "value")
+	cfg := NewConfig()
+
+	got := mustExpand(t, "a=$PARRY_NAME b=$OTHER", cfg)
+	want := "a=app b=value"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package parry
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// resolvePrefixMatch implements `${@prefix:APP_}`: gather every process
+// environment variable whose name starts with prefix into a JSON object,
+// stripping the prefix from each key. encoding/json sorts map keys when
+// marshaling a map[string]string, so the result has stable, sorted key
+// order without needing to sort explicitly.
+func resolvePrefixMatch(prefix string) string {
+	matches := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, value, found := strings.Cut(kv, "=")
+		if !found || prefix == "" || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		matches[strings.TrimPrefix(name, prefix)] = value
+	}
+
+	data, err := json.Marshal(matches)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
@@ -0,0 +1,23 @@
+package parry
+
+import "testing"
+
+func TestPrefixMatchGathersNamespacedVarsIntoSortedJSON(t *testing.T) {
+	t.Setenv("APP_NAME", "widget")
+	t.Setenv("APP_PORT", "8080")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("OTHER_VAR", "ignored")
+
+	got := mustExpand(t, "${@prefix:APP_}", NewConfig())
+	want := `{"DEBUG":"true","NAME":"widget","PORT":"8080"}`
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrefixMatchNoMatchesYieldsEmptyObject(t *testing.T) {
+	got := mustExpand(t, "${@prefix:NO_SUCH_PREFIX_}", NewConfig())
+	if got != "{}" {
+		t.Fatalf("got %q, want %q", got, "{}")
+	}
+}
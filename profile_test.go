@@ -0,0 +1,48 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfileReferenceResolvesAgainstNamedProfileLayer(t *testing.T) {
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"prod.env":    "DB_HOST=prod-db\n",
+		"staging.env": "DB_HOST=staging-db\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(confDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer os.Unsetenv("DB_HOST")
+
+	tmpl := filepath.Join(dir, "in.tmpl")
+	content := "active=$DB_HOST prod=${@profile:prod:DB_HOST}"
+	if err := os.WriteFile(tmpl, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetEnvDir(confDir)
+
+	want := "active=staging-db prod=prod-db"
+	if got := captureStdout(t, func() { GetOutput(cfg) }); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestProfileReferenceMissesWhenProfileWasNeverLoaded(t *testing.T) {
+	cfg := NewConfig()
+	got := mustExpand(t, "${@profile:prod:DB_HOST}", cfg)
+	if got != "" {
+		t.Fatalf("got %q, want empty string", got)
+	}
+}
@@ -0,0 +1,270 @@
+package parry
+
+import "strings"
+
+// SegmentType classifies a run of the payload produced by tokenizeByQuotes.
+type SegmentType int
+
+const (
+	unquoted SegmentType = iota
+	singleQuoted
+	doubleQuoted
+	// ansiCQuoted marks a $'...' span (ANSI-C quoting): its Start includes
+	// the leading $, and its escape sequences are interpreted via
+	// escapeHandler during reassembly instead of passing through raw like
+	// singleQuoted text does.
+	ansiCQuoted
+)
+
+// segment is a contiguous run of one SegmentType, as rune offsets.
+type segment struct {
+	Type  SegmentType
+	Start int
+	End   int
+}
+
+// tokenizeByQuotes splits payload into unquoted/single-quoted/double-quoted/
+// ANSI-C-quoted segments, honoring backslash-escaped quote characters. A
+// single quote immediately preceded by an unescaped $ opens an ansiCQuoted
+// segment (covering the $ itself) instead of a plain singleQuoted one. It
+// returns a *ParseError if payload ends while a quote is still open.
+func tokenizeByQuotes(payload []rune) ([]segment, error) {
+	var segments []segment
+	start := 0
+	cur := unquoted
+	prevDollar := false
+
+	flush := func(end int) {
+		if end > start {
+			segments = append(segments, segment{Type: cur, Start: start, End: end})
+		}
+		start = end
+	}
+
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		if c == '\\' && i+1 < len(payload) {
+			i++
+			prevDollar = false
+			continue
+		}
+		switch cur {
+		case unquoted:
+			switch {
+			case c == '\'' && prevDollar:
+				flush(i - 1)
+				cur = ansiCQuoted
+				start = i - 1
+			case c == '\'':
+				flush(i)
+				cur = singleQuoted
+				start = i
+			case c == '"':
+				flush(i)
+				cur = doubleQuoted
+				start = i
+			}
+		case singleQuoted:
+			if c == '\'' {
+				flush(i + 1)
+				cur = unquoted
+				start = i + 1
+			}
+		case doubleQuoted:
+			if c == '"' {
+				flush(i + 1)
+				cur = unquoted
+				start = i + 1
+			}
+		case ansiCQuoted:
+			if c == '\'' {
+				flush(i + 1)
+				cur = unquoted
+				start = i + 1
+			}
+		}
+		prevDollar = cur == unquoted && c == '$'
+	}
+
+	unmatchedStart := -1
+	if cur != unquoted {
+		unmatchedStart = start
+	}
+	flush(len(payload))
+	if unmatchedStart >= 0 {
+		line, column := lineAndColumn(newlineOffsets(payload), unmatchedStart)
+		return segments, &ParseError{
+			Fragment: string(payload[unmatchedStart:]),
+			Position: unmatchedStart,
+			Message:  "unmatched quote",
+			Line:     line,
+			Column:   column,
+			Class:    ClassParse,
+		}
+	}
+	return segments, nil
+}
+
+// getValidSlices returns the rune ranges in which param expansion applies:
+// everything except single-quoted and ANSI-C-quoted ($'...') regions,
+// unless cfg.ignoreQuotes is set (the whole payload is then one valid
+// range) or cfg.expandSingleQuotes is set (those regions become valid too,
+// but -- unlike ignoreQuotes -- the quote characters themselves stay in the
+// output, since reassemble never touches text outside a param's span).
+func getValidSlices(payload []rune, cfg *Config) ([][2]int, error) {
+	if cfg != nil && cfg.ignoreQuotes {
+		return [][2]int{{0, len(payload)}}, nil
+	}
+
+	segments, err := tokenizeByQuotes(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	expandSingleQuotes := cfg != nil && cfg.expandSingleQuotes
+	var slices [][2]int
+	for _, seg := range segments {
+		if (seg.Type == singleQuoted || seg.Type == ansiCQuoted) && !expandSingleQuotes {
+			continue
+		}
+		slices = append(slices, [2]int{seg.Start, seg.End})
+	}
+	return slices, nil
+}
+
+// ansiCSpans returns the rune ranges of $'...'-style ANSI-C-quoted segments
+// in payload, for interpreting their escape sequences during reassembly.
+func ansiCSpans(payload []rune) ([][2]int, error) {
+	segments, err := tokenizeByQuotes(payload)
+	if err != nil {
+		return nil, err
+	}
+	var spans [][2]int
+	for _, seg := range segments {
+		if seg.Type == ansiCQuoted {
+			spans = append(spans, [2]int{seg.Start, seg.End})
+		}
+	}
+	return spans, nil
+}
+
+// renderLiteral copies payload[start:end) verbatim, except that any
+// ansiCQuoted span within the range has its escape sequences interpreted
+// via escapeHandler, leaving the surrounding $' and ' delimiters literal --
+// the same "keep the quote characters" convention getValidSlices documents
+// for single-quoted text.
+func renderLiteral(payload []rune, start, end int, spans [][2]int) string {
+	var b strings.Builder
+	cursor := start
+	for _, span := range spans {
+		if span[0] < cursor || span[1] > end {
+			continue
+		}
+		b.WriteString(string(payload[cursor:span[0]]))
+		b.WriteString("$'")
+		b.WriteString(escapeHandler(string(payload[span[0]+2 : span[1]-1])))
+		b.WriteString("'")
+		cursor = span[1]
+	}
+	b.WriteString(string(payload[cursor:end]))
+	return b.String()
+}
+
+// inRange reports whether [start, end) falls entirely within one of slices.
+func inRange(start, end int, slices [][2]int) bool {
+	for _, s := range slices {
+		if start >= s[0] && end <= s[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// filterParamsInRange returns the subset of params whose span falls
+// entirely within one of slices. Both params and slices are sorted
+// ascending by position (params by Position[0], slices by Start) and
+// slices never overlap, so rather than testing every param against every
+// slice with inRange -- O(params * slices) -- this walks both in lockstep
+// with a single shared cursor, advancing it past any slice that ends
+// before the current param does. That's safe because later params only
+// have equal or greater starts, so an earlier slice can never satisfy them
+// once it's been passed over. Overall cost is O(params + slices), which
+// matters once either grows into the thousands.
+func filterParamsInRange(params []Param, slices [][2]int) []Param {
+	if len(params) == 0 || len(slices) == 0 {
+		return nil
+	}
+	var result []Param
+	i := 0
+	for _, p := range params {
+		for i < len(slices)-1 && slices[i][1] < p.Position[1] {
+			i++
+		}
+		s := slices[i]
+		if p.Position[0] >= s[0] && p.Position[1] <= s[1] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// paramAtOffset returns the single param from params whose span contains
+// offset, as a 0-or-1-length slice, for --at: every processFile mode
+// already treats "resolve exactly these params, leave the rest of the
+// payload literal" as its ordinary case, so narrowing params down to one
+// entry here is enough to make --at behave correctly everywhere (expand,
+// --report, --list) without any mode-specific handling.
+func paramAtOffset(params []Param, offset int) []Param {
+	for _, p := range params {
+		if offset >= p.Position[0] && offset < p.Position[1] {
+			return []Param{p}
+		}
+	}
+	return nil
+}
+
+// escapeHandler interprets common backslash escape sequences (\n, \t, \\,
+// \", \') in s and returns the literal result.
+func escapeHandler(s string) string {
+	var b []rune
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			switch runes[i+1] {
+			case 'n':
+				b = append(b, '\n')
+				i++
+				continue
+			case 't':
+				b = append(b, '\t')
+				i++
+				continue
+			case 'r':
+				b = append(b, '\r')
+				i++
+				continue
+			case '\\':
+				b = append(b, '\\')
+				i++
+				continue
+			case '"':
+				b = append(b, '"')
+				i++
+				continue
+			case '\'':
+				b = append(b, '\'')
+				i++
+				continue
+			}
+		}
+		b = append(b, runes[i])
+	}
+	return string(b)
+}
+
+// quoteHandler processes escape sequences within a double-quoted segment's
+// inner text, leaving everything else untouched. It returns an error so
+// callers embedding parry don't need to catch a panic for malformed input.
+func quoteHandler(inner string) (string, error) {
+	return escapeHandler(inner), nil
+}
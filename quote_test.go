@@ -0,0 +1,201 @@
+package parry
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTokenizeByQuotesUnmatchedDoubleQuote(t *testing.T) {
+	_, err := tokenizeByQuotes([]rune(`echo "unterminated`))
+	if err == nil {
+		t.Fatal("expected an error for an unterminated double quote")
+	}
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+}
+
+func TestTokenizeByQuotesUnmatchedSingleQuote(t *testing.T) {
+	_, err := tokenizeByQuotes([]rune(`'unterminated`))
+	if err == nil {
+		t.Fatal("expected an error for an unterminated single quote")
+	}
+}
+
+func TestTokenizeByQuotesUnmatchedQuoteReportsLineAndColumn(t *testing.T) {
+	_, err := tokenizeByQuotes([]rune("first line\nsecond \"open"))
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected a *ParseError, got %T", err)
+	}
+	if pe.Line != 2 || pe.Column != 8 {
+		t.Fatalf("got line %d column %d, want line 2 column 8", pe.Line, pe.Column)
+	}
+	if !strings.Contains(pe.Error(), "at line 2, column 8") {
+		t.Fatalf("Error() %q does not mention line/column", pe.Error())
+	}
+}
+
+func TestTokenizeByQuotesBalancedInput(t *testing.T) {
+	segments, err := tokenizeByQuotes([]rune(`a 'b' "c"`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segments) == 0 {
+		t.Fatal("expected at least one segment")
+	}
+}
+
+func TestQuoteHandlerInterpretsEscapes(t *testing.T) {
+	got, err := quoteHandler(`line1\nline2`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "line1\nline2" {
+		t.Fatalf("got %q, want %q", got, "line1\nline2")
+	}
+}
+
+func TestGetValidSlicesPropagatesUnmatchedQuoteError(t *testing.T) {
+	_, err := getValidSlices([]rune(`"unterminated`), NewConfig())
+	if err == nil {
+		t.Fatal("expected an error for an unterminated quote")
+	}
+}
+
+func TestGetValidSlicesIgnoreQuotesSkipsTokenizing(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetIgnoreQuotes(true)
+	slices, err := getValidSlices([]rune(`"unterminated`), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(slices) != 1 {
+		t.Fatalf("expected a single whole-payload slice, got %d", len(slices))
+	}
+}
+
+func TestTokenizeByQuotesClassifiesAnsiCQuoting(t *testing.T) {
+	segments, err := tokenizeByQuotes([]rune(`a $'b\tc' d`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var found bool
+	for _, seg := range segments {
+		if seg.Type == ansiCQuoted {
+			found = true
+			if got := string([]rune(`a $'b\tc' d`)[seg.Start:seg.End]); got != `$'b\tc'` {
+				t.Fatalf("got %q, want %q", got, `$'b\tc'`)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expected an ansiCQuoted segment")
+	}
+}
+
+func TestAnsiCQuotingExpandsEscapesButNotParams(t *testing.T) {
+	os.Setenv("FOO", "bar")
+	defer os.Unsetenv("FOO")
+
+	cfg := NewConfig()
+	payload := []rune(`a $'1\t2\n3 $FOO' b`)
+
+	validSlices, err := getValidSlices(payload, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	allParams, err := findParams(payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var params []Param
+	for _, p := range allParams {
+		if inRange(p.Position[0], p.Position[1], validSlices) {
+			params = append(params, p)
+		}
+	}
+	if len(params) != 0 {
+		t.Fatalf("expected $FOO inside $'...' to be excluded from expansion, got %v", params)
+	}
+
+	values, err := mapParamValues(params, payload, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := reassemble(payload, params, values, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "a $'1\t2\n3 $FOO' b"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestExpandSingleQuotesExpandsInsideSingleQuotesButKeepsThem(t *testing.T) {
+	os.Setenv("FOO", "bar")
+	defer os.Unsetenv("FOO")
+
+	cfg := NewConfig()
+	cfg.SetExpandSingleQuotes(true)
+
+	got := mustExpand(t, `'$FOO'`, cfg)
+	if got != `'bar'` {
+		t.Fatalf("got %q, want %q", got, `'bar'`)
+	}
+}
+
+func TestFilterParamsInRangeMatchesInRangeAcrossGapsAndBoundaries(t *testing.T) {
+	slices := [][2]int{{0, 5}, {10, 20}}
+	params := []Param{
+		{Raw: "a", Position: [2]int{2, 4}},   // fully inside slice 0
+		{Raw: "b", Position: [2]int{6, 8}},   // entirely in the gap
+		{Raw: "c", Position: [2]int{3, 8}},   // straddles slice 0 and the gap
+		{Raw: "d", Position: [2]int{12, 18}}, // fully inside slice 1
+		{Raw: "e", Position: [2]int{18, 22}}, // straddles slice 1 and beyond
+	}
+
+	got := filterParamsInRange(params, slices)
+
+	var want []Param
+	for _, p := range params {
+		if inRange(p.Position[0], p.Position[1], slices) {
+			want = append(want, p)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d params, want %d: %v vs %v", len(got), len(want), got, want)
+	}
+	for i := range want {
+		if got[i].Raw != want[i].Raw {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func BenchmarkFilterParamsInRange(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 5000; i++ {
+		sb.WriteString("Lorem ipsum ${VAR")
+		sb.WriteString(string(rune('A' + i%26)))
+		sb.WriteString(":-default} dolor $OTHER sit amet, \"quoted $SKIP text\". ")
+	}
+	payload := []rune(sb.String())
+
+	cfg := NewConfig()
+	validSlices, err := getValidSlices(payload, cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	allParams, err := findParams(payload)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filterParamsInRange(allParams, validSlices)
+	}
+}
@@ -0,0 +1,123 @@
+package parry
+
+import "strings"
+
+// quoteForValue escapes a resolved value for the config dialect named by
+// dialect (e.g. "ini", "yaml", "make"), as selected by --quote-for. column
+// is the param's 1-based column in the template, used only by "yaml" to
+// indent a multiline value's block scalar past its surrounding key. An
+// empty or unknown dialect returns value unchanged.
+func quoteForValue(value, dialect string, column int) string {
+	switch dialect {
+	case "ini":
+		return quoteForINI(value)
+	case "yaml":
+		return quoteForYAML(value, column)
+	case "make":
+		return quoteForMake(value)
+	case "prometheus":
+		return quoteForPrometheus(value)
+	default:
+		return value
+	}
+}
+
+// quoteForINI escapes value per common INI conventions: a value is wrapped
+// in double quotes when it has leading/trailing whitespace or contains a
+// comment-introducing ';' or '#', either of which would otherwise be
+// truncated or misread by most INI parsers.
+func quoteForINI(value string) string {
+	if !needsINIQuoting(value) {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+func needsINIQuoting(value string) bool {
+	if value == "" {
+		return false
+	}
+	if strings.TrimSpace(value) != value {
+		return true
+	}
+	return strings.ContainsAny(value, ";#")
+}
+
+// quoteForYAML escapes value for insertion as a YAML scalar at the given
+// column: a multiline value becomes a literal block scalar (`|`), indented
+// past column so it nests under whatever key the param followed; a
+// single-line value that would otherwise be misread (leading/trailing
+// whitespace, or a YAML-significant character) is wrapped in a double-quoted
+// scalar instead.
+func quoteForYAML(value string, column int) string {
+	if strings.Contains(value, "\n") {
+		return yamlBlockScalar(value, column)
+	}
+	if !needsYAMLQuoting(value) {
+		return value
+	}
+	return quoteForYAMLScalar(value)
+}
+
+// yamlBlockScalar renders value as a YAML literal block scalar, with every
+// line indented two spaces past column so it reads as nested under the key
+// the param replaced. A trailing newline keeps the default "clip" chomping
+// indicator (`|`); otherwise "strip" (`|-`) is used so reassembling doesn't
+// introduce one.
+func yamlBlockScalar(value string, column int) string {
+	indent := strings.Repeat(" ", column+1)
+	trailingNewline := strings.HasSuffix(value, "\n")
+	lines := strings.Split(strings.TrimSuffix(value, "\n"), "\n")
+
+	var b strings.Builder
+	b.WriteString("|")
+	if !trailingNewline {
+		b.WriteString("-")
+	}
+	for _, line := range lines {
+		b.WriteString("\n")
+		b.WriteString(indent)
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+func needsYAMLQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	if strings.TrimSpace(value) != value {
+		return true
+	}
+	return strings.ContainsAny(value, ":{}[]&*!|>'\"%@`#,")
+}
+
+func quoteForYAMLScalar(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	return `"` + escaped + `"`
+}
+
+// quoteForMake escapes value for insertion into a Makefile fragment: every
+// `$` is doubled to `$$` so Make's own variable expansion doesn't try to
+// interpret it a second time, and every `#` is backslash-escaped so it
+// doesn't open a comment partway through the value. A literal tab is left
+// untouched -- it's only special to Make at the start of a recipe line,
+// never as part of a value.
+func quoteForMake(value string) string {
+	value = strings.ReplaceAll(value, "$", "$$")
+	value = strings.ReplaceAll(value, "#", `\#`)
+	return value
+}
+
+// quoteForPrometheus escapes value per the Prometheus text exposition
+// format's label-value escaping rules: a backslash becomes `\\`, a double
+// quote becomes `\"`, and a newline becomes the two-character escape `\n`,
+// so the value stays on a single line and survives as the literal content
+// of a quoted label value.
+func quoteForPrometheus(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return value
+}
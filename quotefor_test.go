@@ -0,0 +1,143 @@
+package parry
+
+import (
+	"os"
+	"testing"
+)
+
+func TestQuoteForINI(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"trailing space", "value ", `"value "`},
+		{"embedded semicolon", "a;b", `"a;b"`},
+		{"embedded hash", "a#b", `"a#b"`},
+		{"plain value unquoted", "plain", "plain"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := quoteForValue(c.value, "ini", 1)
+			if got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestQuoteForYAMLMultilineValueProducesBlockScalar(t *testing.T) {
+	got := quoteForValue("first line\nsecond line", "yaml", 7)
+	want := "|-\n        first line\n        second line"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestQuoteForYAMLSpecialCharSingleLineValueIsQuoted(t *testing.T) {
+	got := quoteForValue("a: b", "yaml", 7)
+	want := `"a: b"`
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestQuoteForYAMLAppliesDuringExpansion(t *testing.T) {
+	os.Setenv("YAML_VAL", "line one\nline two")
+	defer os.Unsetenv("YAML_VAL")
+
+	cfg := NewConfig()
+	cfg.SetQuoteFor("yaml")
+
+	got := mustExpand(t, "key: $YAML_VAL", cfg)
+	want := "key: |-\n       line one\n       line two"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestQuoteForMake(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"dollar sign doubled", "price: $5", "price: $$5"},
+		{"hash escaped", "a#b", `a\#b`},
+		{"tab passes through unchanged", "a\tb", "a\tb"},
+		{"plain value unchanged", "plain", "plain"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := quoteForValue(c.value, "make", 1)
+			if got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestQuoteForMakeAppliesDuringExpansion(t *testing.T) {
+	os.Setenv("MAKE_VAL", "$HOME/bin")
+	defer os.Unsetenv("MAKE_VAL")
+
+	cfg := NewConfig()
+	cfg.SetQuoteFor("make")
+
+	got := mustExpand(t, "PATH := $MAKE_VAL", cfg)
+	want := "PATH := $$HOME/bin"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestQuoteForAppliesDuringExpansion(t *testing.T) {
+	os.Setenv("VAL", "a;b")
+	defer os.Unsetenv("VAL")
+
+	cfg := NewConfig()
+	cfg.SetQuoteFor("ini")
+
+	got, err := parseEmbeddedParams("name=$VAL", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != `name="a;b"` {
+		t.Fatalf("got %q, want %q", got, `name="a;b"`)
+	}
+}
+
+func TestQuoteForPrometheus(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"quote escaped", `say "hi"`, `say \"hi\"`},
+		{"backslash escaped", `a\b`, `a\\b`},
+		{"newline escaped", "a\nb", `a\nb`},
+		{"plain value unchanged", "plain", "plain"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := quoteForValue(c.value, "prometheus", 1)
+			if got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestQuoteForPrometheusAppliesDuringExpansion(t *testing.T) {
+	os.Setenv("PROM_VAL", "line1\nline2 \"quoted\" \\path")
+	defer os.Unsetenv("PROM_VAL")
+
+	cfg := NewConfig()
+	cfg.SetQuoteFor("prometheus")
+
+	got := mustExpand(t, `label{value="$PROM_VAL"}`, cfg)
+	want := `label{value="line1\nline2 \"quoted\" \\path"}`
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package parry
+
+import "sort"
+
+// SourceTrace records whether one environment source could provide a given
+// variable, and with what value.
+type SourceTrace struct {
+	Source  string `json:"source"`
+	Value   string `json:"value"`
+	Present bool   `json:"present"`
+}
+
+// ReportEntry is the full precedence trace for one variable: every source
+// that could provide it, in precedence order, and which one won.
+type ReportEntry struct {
+	Name    string        `json:"name"`
+	Sources []SourceTrace `json:"sources"`
+	Winner  string        `json:"winner"`
+}
+
+// Environment captures the layered sources parry resolves variables
+// against, in ascending precedence order, so --report can trace which
+// source wins for a given name.
+type Environment struct {
+	ambient  map[string]string
+	envFiles []namedLayer
+	override map[string]string
+}
+
+type namedLayer struct {
+	name   string
+	values map[string]string
+}
+
+func newEnvironment() *Environment {
+	return &Environment{override: make(map[string]string)}
+}
+
+func (e *Environment) setAmbient(values map[string]string) {
+	e.ambient = values
+}
+
+func (e *Environment) addEnvFile(name string, values map[string]string) {
+	e.envFiles = append(e.envFiles, namedLayer{name: name, values: values})
+}
+
+func (e *Environment) setOverride(values map[string]string) {
+	e.override = values
+}
+
+// trace returns, in precedence order, every source that could provide name
+// and which one wins.
+func (e *Environment) trace(name string) ReportEntry {
+	entry := ReportEntry{Name: name}
+
+	if v, ok := e.ambient[name]; ok {
+		entry.Sources = append(entry.Sources, SourceTrace{Source: "ambient", Value: v, Present: true})
+		entry.Winner = "ambient"
+	} else {
+		entry.Sources = append(entry.Sources, SourceTrace{Source: "ambient", Present: false})
+	}
+
+	for _, layer := range e.envFiles {
+		if v, ok := layer.values[name]; ok {
+			entry.Sources = append(entry.Sources, SourceTrace{Source: layer.name, Value: v, Present: true})
+			entry.Winner = layer.name
+		} else {
+			entry.Sources = append(entry.Sources, SourceTrace{Source: layer.name, Present: false})
+		}
+	}
+
+	if v, ok := e.override[name]; ok {
+		entry.Sources = append(entry.Sources, SourceTrace{Source: "override", Value: v, Present: true})
+		entry.Winner = "override"
+	} else {
+		entry.Sources = append(entry.Sources, SourceTrace{Source: "override", Present: false})
+	}
+
+	return entry
+}
+
+// unusedProvidedNames returns, sorted, every name in e's --envfile/--envtoml/
+// --env-dir/--envfile-if layers or -e overrides that referenced never marks
+// as consulted -- for --report-unused, to catch a stale or misspelled
+// override. The inherited ambient environment is excluded, since those
+// values were never provided for this run in the first place.
+func unusedProvidedNames(e *Environment, referenced map[string]bool) []string {
+	provided := make(map[string]bool)
+	for _, layer := range e.envFiles {
+		for name := range layer.values {
+			provided[name] = true
+		}
+	}
+	for name := range e.override {
+		provided[name] = true
+	}
+
+	var unused []string
+	for name := range provided {
+		if !referenced[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// buildReport traces every distinct name in names, sorted for stable
+// output.
+func buildReport(e *Environment, names []string) []ReportEntry {
+	unique := make(map[string]bool, len(names))
+	var sorted []string
+	for _, n := range names {
+		if !unique[n] {
+			unique[n] = true
+			sorted = append(sorted, n)
+		}
+	}
+	sort.Strings(sorted)
+
+	entries := make([]ReportEntry, 0, len(sorted))
+	for _, n := range sorted {
+		entries = append(entries, e.trace(n))
+	}
+	return entries
+}
@@ -0,0 +1,43 @@
+package parry
+
+import "testing"
+
+func TestEnvironmentTraceAcrossThreeSources(t *testing.T) {
+	env := newEnvironment()
+	env.setAmbient(map[string]string{"FOO": "ambient-val"})
+	env.addEnvFile("base.env", map[string]string{"FOO": "envfile-val"})
+	env.setOverride(map[string]string{"FOO": "override-val"})
+
+	entry := env.trace("FOO")
+
+	if entry.Winner != "override" {
+		t.Fatalf("winner: got %q, want %q", entry.Winner, "override")
+	}
+	if len(entry.Sources) != 3 {
+		t.Fatalf("expected 3 sources in the trace, got %d: %+v", len(entry.Sources), entry.Sources)
+	}
+	want := []SourceTrace{
+		{Source: "ambient", Value: "ambient-val", Present: true},
+		{Source: "base.env", Value: "envfile-val", Present: true},
+		{Source: "override", Value: "override-val", Present: true},
+	}
+	for i, w := range want {
+		if entry.Sources[i] != w {
+			t.Fatalf("source %d: got %+v, want %+v", i, entry.Sources[i], w)
+		}
+	}
+}
+
+func TestEnvironmentTraceMissingSource(t *testing.T) {
+	env := newEnvironment()
+	env.setAmbient(map[string]string{})
+	env.setOverride(map[string]string{"FOO": "only-here"})
+
+	entry := env.trace("FOO")
+	if entry.Winner != "override" {
+		t.Fatalf("winner: got %q, want %q", entry.Winner, "override")
+	}
+	if entry.Sources[0].Present {
+		t.Fatalf("expected ambient source to be absent, got %+v", entry.Sources[0])
+	}
+}
@@ -0,0 +1,71 @@
+package parry
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	orig := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = w
+	fn()
+	w.Close()
+	os.Stderr = orig
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestReportUnusedListsOnlyVariablesNeverReferenced(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/tmpl"
+	if err := os.WriteFile(path, []byte("a=$REPORT_UNUSED_A b=$REPORT_UNUSED_B"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.AddEnv("REPORT_UNUSED_A=1")
+	cfg.AddEnv("REPORT_UNUSED_B=2")
+	cfg.AddEnv("REPORT_UNUSED_C=3")
+	cfg.SetReportUnused(true)
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() {
+			GetOutput(cfg)
+		})
+	})
+	if !strings.Contains(stderr, "REPORT_UNUSED_C") {
+		t.Fatalf("expected unused REPORT_UNUSED_C reported, got %q", stderr)
+	}
+	if strings.Contains(stderr, "REPORT_UNUSED_A") || strings.Contains(stderr, "REPORT_UNUSED_B") {
+		t.Fatalf("expected referenced variables not reported, got %q", stderr)
+	}
+}
+
+func TestReportUnusedSilentWhenEveryProvidedVariableIsReferenced(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/tmpl"
+	if err := os.WriteFile(path, []byte("a=$REPORT_UNUSED_ONLY"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	cfg.AddEnv("REPORT_UNUSED_ONLY=1")
+	cfg.SetReportUnused(true)
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() {
+			GetOutput(cfg)
+		})
+	})
+	if stderr != "" {
+		t.Fatalf("expected no unused-variable output, got %q", stderr)
+	}
+}
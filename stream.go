@@ -0,0 +1,177 @@
+package parry
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// maxLookaheadBytes bounds how far ExpandStream will grow its pending
+// buffer while waiting for a `${...}` expression or a quoted segment to
+// close. A template with more unclosed text than this between a `${`/`'`/
+// `"` and its close is reported as a parse error instead of being
+// buffered forever.
+const maxLookaheadBytes = 1 << 20 // 1 MiB
+
+// ExpandStream expands $VAR/${VAR} params while reading from r and writing
+// to w incrementally, so the whole input never has to fit in memory at
+// once. It reads and expands in chunks sized by cfg.readBufferSize(),
+// holding back only as much text as findSafeCut says might still be part
+// of a `${...}` expression or a quoted segment that straddles the chunk
+// boundary -- see findSafeCut for the look-ahead rules.
+func ExpandStream(r io.Reader, w io.Writer, cfg Config) error {
+	br := bufio.NewReaderSize(r, cfg.readBufferSize())
+	chunk := make([]byte, cfg.readBufferSize())
+
+	var pending []rune
+	// nextScan holds off re-running findSafeCut over the whole pending
+	// buffer until it's grown at least this long. Without it, a template
+	// with an unterminated `${`/quote never produces a safe cut, so every
+	// read rescans the entire (ever-growing) pending buffer from scratch --
+	// making the cost of reaching maxLookaheadBytes quadratic in the
+	// number of chunks read. Doubling the checkpoint each time a rescan
+	// still finds nothing safe keeps the total rescan cost linear, while
+	// capping it once pending is within one read of maxLookaheadBytes
+	// keeps the overrun check exact, the same as if it ran every time.
+	nextScan := 0
+	for {
+		n, readErr := br.Read(chunk)
+		if n > 0 {
+			pending = append(pending, []rune(string(chunk[:n]))...)
+		}
+		eof := readErr == io.EOF
+		if readErr != nil && !eof {
+			return readErr
+		}
+
+		cut := 0
+		switch {
+		case eof:
+			cut = len(pending)
+		case len(pending) >= nextScan:
+			cut = findSafeCut(pending)
+			if len(pending)-cut > maxLookaheadBytes {
+				return &ParseError{
+					Fragment: string(pending[cut:]),
+					Position: cut,
+					Message:  "unterminated param or quote exceeds max lookahead",
+					Class:    ClassParse,
+				}
+			}
+			if cut > 0 {
+				nextScan = 0
+			} else {
+				nextScan = len(pending) * 2
+				if nextScan > maxLookaheadBytes {
+					nextScan = len(pending) + 1
+				}
+			}
+		}
+
+		if cut > 0 {
+			out, err := expandChunk(pending[:cut], &cfg)
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, out); err != nil {
+				return err
+			}
+			pending = pending[cut:]
+		}
+
+		if eof {
+			if len(pending) > 0 {
+				out, err := expandChunk(pending, &cfg)
+				if err != nil {
+					return err
+				}
+				if _, err := io.WriteString(w, out); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// expandChunk runs the normal tokenize -> findParams -> resolve ->
+// reassemble pipeline over a self-contained chunk, i.e. one findSafeCut
+// has already guaranteed has no straddling quote or param.
+func expandChunk(payload []rune, cfg *Config) (string, error) {
+	validSlices, err := getValidSlices(payload, cfg)
+	if err != nil {
+		return "", err
+	}
+	allParams, err := findParams(payload)
+	if err != nil {
+		return "", err
+	}
+	params := filterParamsInRange(allParams, validSlices)
+	values, err := mapParamValues(params, payload, cfg)
+	if err != nil {
+		return "", err
+	}
+	return reassemble(payload, params, values, cfg)
+}
+
+// findSafeCut returns the largest prefix length of pending that is known
+// to contain no partially-read `${...}` expression or quoted segment, so
+// it can be expanded and written out immediately. Everything from the
+// returned index onward is held back and prepended to the next read.
+func findSafeCut(pending []rune) int {
+	cut := len(pending)
+
+	// tokenizeByQuotes only errors when a quote it opened is still open at
+	// the end of pending -- exactly the case we need to hold back from.
+	if _, err := tokenizeByQuotes(pending); err != nil {
+		if pe, ok := err.(*ParseError); ok && pe.Position < cut {
+			cut = pe.Position
+		}
+	}
+
+	if idx := firstUnresolvedParamStart(pending[:cut]); idx >= 0 {
+		cut = idx
+	}
+
+	return cut
+}
+
+// firstUnresolvedParamStart returns the rune offset of the earliest `$`
+// in payload whose param expression isn't guaranteed complete -- either
+// an unterminated `${...}` (no closing `}` anywhere in payload, so
+// everything after it is ambiguous) or a bare `$NAME` running up against
+// the very end of payload, where NAME could still gain more characters
+// from the next chunk -- or -1 if neither applies.
+func firstUnresolvedParamStart(payload []rune) int {
+	for i := 0; i < len(payload); i++ {
+		if payload[i] != '$' || (i > 0 && payload[i-1] == '\\') {
+			continue
+		}
+		if i+1 < len(payload) && payload[i+1] == '{' {
+			if !strings.Contains(string(payload[i:]), "}") {
+				return i
+			}
+			continue
+		}
+	}
+
+	n := len(payload)
+	if n == 0 {
+		return -1
+	}
+	j := n
+	for j > 0 && isIdentifierChar(payload[j-1]) {
+		j--
+	}
+	if j < n && j > 0 && payload[j-1] == '$' && (j == 1 || payload[j-2] != '\\') {
+		return j - 1
+	}
+	if payload[n-1] == '$' {
+		return n - 1
+	}
+	return -1
+}
+
+func isIdentifierChar(r rune) bool {
+	return r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
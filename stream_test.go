@@ -0,0 +1,118 @@
+package parry
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExpandStreamMatchesExpandAcrossBufferSizes(t *testing.T) {
+	t.Setenv("STREAM_VAR", "world")
+	input := "hello ${STREAM_VAR}, 'literal ${STREAM_VAR}', \"quoted ${STREAM_VAR}\" and ${MISSING:-fallback}"
+
+	want, err := Expand(input)
+	if err != nil {
+		t.Fatalf("Expand: %v", err)
+	}
+
+	// Buffer sizes deliberately land mid-identifier and mid-brace for
+	// "${STREAM_VAR}" at several different offsets, exercising the chunk
+	// seam findSafeCut is responsible for protecting.
+	for _, size := range []int{1, 2, 3, 5, 8, 13, 64, 4096} {
+		cfg := NewConfig()
+		cfg.SetReadBuffer(size)
+		var out bytes.Buffer
+		if err := ExpandStream(strings.NewReader(input), &out, *cfg); err != nil {
+			t.Fatalf("bufSize=%d: %v", size, err)
+		}
+		if out.String() != want {
+			t.Fatalf("bufSize=%d: got %q, want %q", size, out.String(), want)
+		}
+	}
+}
+
+func TestExpandStreamSplitsParamExactlyAtBraceBoundary(t *testing.T) {
+	t.Setenv("SEAM_VAR", "ok")
+	input := "prefix ${SEAM_VAR} suffix"
+
+	// Force the chunk boundary to land right after the opening "${" so the
+	// param's name and closing brace only become available on the next
+	// read.
+	for _, size := range []int{len("prefix ${"), len("prefix ${SEAM"), len("prefix ${SEAM_VAR")} {
+		cfg := NewConfig()
+		cfg.SetReadBuffer(size)
+		var out bytes.Buffer
+		if err := ExpandStream(strings.NewReader(input), &out, *cfg); err != nil {
+			t.Fatalf("bufSize=%d: %v", size, err)
+		}
+		if out.String() != "prefix ok suffix" {
+			t.Fatalf("bufSize=%d: got %q, want %q", size, out.String(), "prefix ok suffix")
+		}
+	}
+}
+
+func TestExpandStreamSplitsQuoteAcrossBoundary(t *testing.T) {
+	t.Setenv("QUOTE_SEAM", "x")
+	input := `before "mid ${QUOTE_SEAM} text" after`
+
+	for _, size := range []int{len(`before "mid `), len(`before "mid ${QUOTE_SEAM}`)} {
+		cfg := NewConfig()
+		cfg.SetReadBuffer(size)
+		var out bytes.Buffer
+		if err := ExpandStream(strings.NewReader(input), &out, *cfg); err != nil {
+			t.Fatalf("bufSize=%d: %v", size, err)
+		}
+		want := `before "mid x text" after`
+		if out.String() != want {
+			t.Fatalf("bufSize=%d: got %q, want %q", size, out.String(), want)
+		}
+	}
+}
+
+func TestExpandStreamPropagatesResolutionErrors(t *testing.T) {
+	cfg := NewConfig()
+	var out bytes.Buffer
+	err := ExpandStream(strings.NewReader("${MISSING_REQUIRED:?must be set}"), &out, *cfg)
+	if err == nil {
+		t.Fatal("expected an error for an unset required param")
+	}
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+}
+
+func TestExpandStreamErrorsOnLookaheadOverrun(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetReadBuffer(64)
+	input := "${" + strings.Repeat("x", maxLookaheadBytes+10)
+	var out bytes.Buffer
+	err := ExpandStream(strings.NewReader(input), &out, *cfg)
+	if err == nil {
+		t.Fatal("expected an error for a param that never closes within the lookahead bound")
+	}
+}
+
+func TestFindSafeCutHoldsBackIncompleteConstructs(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want int
+	}{
+		{"complete text has no holdback", "hello world", len("hello world")},
+		{"trailing bare dollar", "hello $", len("hello ")},
+		{"trailing identifier", "hello $FOO", len("hello ")},
+		{"unterminated brace", "hello ${FOO", len("hello ")},
+		{"complete braced param", "hello ${FOO} more", len("hello ${FOO} more")},
+		{"open double quote", `hello "world`, len("hello ")},
+		{"closed quote", `hello "world" more`, len(`hello "world" more`)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := findSafeCut([]rune(c.in))
+			if got != c.want {
+				t.Fatalf("%s: got cut=%d, want %d", fmt.Sprintf("%q", c.in), got, c.want)
+			}
+		})
+	}
+}
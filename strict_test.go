@@ -0,0 +1,48 @@
+package parry
+
+import "testing"
+
+func TestStrictFailsListingEveryUndefinedVariable(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetStrict(true)
+	_, err := mustExpandErr(t, "host=$HOST port=${PORT} name=$HOST", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Fragment != "HOST, PORT" {
+		t.Fatalf("got fragment %q, want %q", pe.Fragment, "HOST, PORT")
+	}
+}
+
+func TestStrictAllowsDefaultAndPlusForms(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetStrict(true)
+	got, err := mustExpandErr(t, "a=${MISSING:-fallback} b=${MISSING+x}", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "a=fallback b=" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestStrictPassesWhenEveryVariableIsSet(t *testing.T) {
+	t.Setenv("STRICT_OK", "1")
+	cfg := NewConfig()
+	cfg.SetStrict(true)
+	got, err := mustExpandErr(t, "v=$STRICT_OK", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "v=1" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+// mustExpandErr expands tmpl against cfg, returning the result and error
+// without panicking, for tests that need to inspect the error value itself.
+func mustExpandErr(t *testing.T, tmpl string, cfg *Config) (string, error) {
+	t.Helper()
+	return parseEmbeddedParams(tmpl, cfg)
+}
@@ -0,0 +1,30 @@
+package parry
+
+import "testing"
+
+func TestStrictBracesRejectsBareVar(t *testing.T) {
+	t.Setenv("FOO", "bar")
+	cfg := NewConfig()
+	cfg.SetStrictBraces(true)
+	_, err := mustExpandErr(t, "$FOO", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Position != 0 {
+		t.Fatalf("got position %d, want 0", pe.Position)
+	}
+}
+
+func TestStrictBracesAllowsBracedVar(t *testing.T) {
+	t.Setenv("FOO", "bar")
+	cfg := NewConfig()
+	cfg.SetStrictBraces(true)
+	got, err := mustExpandErr(t, "${FOO}", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "bar" {
+		t.Fatalf("got %q, want %q", got, "bar")
+	}
+}
@@ -0,0 +1,54 @@
+package parry
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ExpandStruct runs Expand against data's exported fields instead of the
+// process environment: $VAR/${VAR} resolves to the field named VAR, and a
+// dotted name like ${Address.City} walks into a nested struct field.
+// Non-string fields are stringified via fmt.Sprint. data must be a struct
+// or a pointer to one.
+func ExpandStruct(input string, data any) (string, error) {
+	return Expand(input, WithLookup(structLookup(data)))
+}
+
+// structLookup resolves name against data's exported fields, following a
+// dotted name through nested structs one segment at a time.
+func structLookup(data any) func(name string) (string, bool) {
+	return func(name string) (string, bool) {
+		v := reflect.ValueOf(data)
+		for _, part := range strings.Split(name, ".") {
+			v = indirect(v)
+			if v.Kind() != reflect.Struct {
+				return "", false
+			}
+			v = v.FieldByName(part)
+			if !v.IsValid() || !v.CanInterface() {
+				return "", false
+			}
+		}
+		v = indirect(v)
+		if !v.IsValid() || !v.CanInterface() {
+			return "", false
+		}
+		if v.Kind() == reflect.String {
+			return v.String(), true
+		}
+		return fmt.Sprint(v.Interface()), true
+	}
+}
+
+// indirect dereferences v until it's no longer a pointer, returning the
+// zero Value if it hits a nil pointer along the way.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
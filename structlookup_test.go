@@ -0,0 +1,61 @@
+package parry
+
+import "testing"
+
+type structLookupAddress struct {
+	City string
+}
+
+type structLookupConfig struct {
+	Name    string
+	Port    int
+	Address structLookupAddress
+}
+
+func TestExpandStructResolvesStringAndIntFields(t *testing.T) {
+	data := structLookupConfig{Name: "api", Port: 8080}
+
+	got, err := ExpandStruct("$Name:$Port", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "api:8080" {
+		t.Fatalf("got %q, want %q", got, "api:8080")
+	}
+}
+
+func TestExpandStructResolvesNestedFieldViaDottedName(t *testing.T) {
+	data := structLookupConfig{Address: structLookupAddress{City: "Berlin"}}
+
+	got, err := ExpandStruct("${Address.City}", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "Berlin" {
+		t.Fatalf("got %q, want %q", got, "Berlin")
+	}
+}
+
+func TestExpandStructMissingFieldResolvesEmpty(t *testing.T) {
+	data := structLookupConfig{}
+
+	got, err := ExpandStruct("${Missing}", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("got %q, want empty string", got)
+	}
+}
+
+func TestExpandStructAcceptsPointer(t *testing.T) {
+	data := &structLookupConfig{Name: "api"}
+
+	got, err := ExpandStruct("$Name", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "api" {
+		t.Fatalf("got %q, want %q", got, "api")
+	}
+}
@@ -0,0 +1,74 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetTeeWritesIdenticalContentToStdoutAndFile(t *testing.T) {
+	t.Setenv("TEE_VAR", "hi")
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	teePath := filepath.Join(dir, "tee.out")
+	if err := os.WriteFile(tmpl, []byte("value=$TEE_VAR"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetTee(teePath)
+
+	stdout := captureStdout(t, func() { GetOutput(cfg) })
+
+	got, err := os.ReadFile(teePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout != "value=hi" {
+		t.Fatalf("got stdout %q, want %q", stdout, "value=hi")
+	}
+	if string(got) != stdout {
+		t.Fatalf("tee'd file %q does not match stdout %q", got, stdout)
+	}
+}
+
+func TestSetTeeCombinedWithEditInPlacePanics(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetTee(filepath.Join(dir, "tee.out"))
+	cfg.SetEditInPlace(true)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected a panic combining --tee with -i")
+		}
+	}()
+	GetOutput(cfg)
+}
+
+func TestSetTeeCombinedWithOutputPanics(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetTee(filepath.Join(dir, "tee.out"))
+	cfg.SetOutput(filepath.Join(dir, "out.conf"))
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected a panic combining --tee with -o/--output")
+		}
+	}()
+	GetOutput(cfg)
+}
@@ -0,0 +1,67 @@
+package parry
+
+import "testing"
+
+func TestValidatePassesWhenValueMatchesPattern(t *testing.T) {
+	t.Setenv("PORT", "8080")
+	cfg := NewConfig()
+	cfg.AddValidate(`PORT=^\d+$`)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := mustExpandErr(t, "port=$PORT", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "port=8080" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestValidateFailsWhenValueDoesNotMatchPattern(t *testing.T) {
+	t.Setenv("PORT", "not-a-port")
+	cfg := NewConfig()
+	cfg.AddValidate(`PORT=^\d+$`)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := mustExpandErr(t, "port=$PORT", cfg)
+	pe, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+	if pe.Fragment != "$PORT" {
+		t.Fatalf("got fragment %q, want %q", pe.Fragment, "$PORT")
+	}
+}
+
+func TestValidateFailsWhenValidatedVariableIsUnset(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddValidate(`PORT=^\d+$`)
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := mustExpandErr(t, "port=$PORT", cfg)
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("got %T, want *ParseError", err)
+	}
+}
+
+func TestValidateRejectsSpecWithoutEquals(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddValidate("PORT")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for --validate spec without '='")
+	}
+}
+
+func TestValidateRejectsInvalidPattern(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddValidate("PORT=[")
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for invalid --validate pattern")
+	}
+}
@@ -0,0 +1,41 @@
+package parry
+
+import (
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestValidateRejectsDirectoryAsTemplateFile(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFile(t.TempDir())
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error for a template file that's actually a directory")
+	}
+}
+
+func TestValidateRejectsMissingTemplateFile(t *testing.T) {
+	cfg := NewConfig()
+	cfg.AddFile(t.TempDir() + "/does-not-exist")
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error for a missing template file")
+	}
+}
+
+func TestValidateRejectsUnreadableTemplateFile(t *testing.T) {
+	if syscall.Getuid() == 0 {
+		t.Skip("running as root, which bypasses file permission checks")
+	}
+	path := t.TempDir() + "/unreadable"
+	if err := os.WriteFile(path, []byte("x"), 0000); err != nil {
+		t.Fatal(err)
+	}
+	cfg := NewConfig()
+	cfg.AddFile(path)
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error for a template file with no read permission")
+	}
+}
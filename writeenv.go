@@ -0,0 +1,77 @@
+package parry
+
+import (
+	"os"
+	"strings"
+)
+
+// referencedNames returns the distinct variable names params referenced,
+// in order of first appearance.
+func referencedNames(params []Param) []string {
+	seen := make(map[string]bool, len(params))
+	names := make([]string, 0, len(params))
+	for _, p := range params {
+		if p.Name == "" || seen[p.Name] {
+			continue
+		}
+		seen[p.Name] = true
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// writeEnvMarker is written as the first line of every --write-env
+// snapshot and checked for by parseEnvFile to decide whether to run
+// unescapeEnvValue over the file's values. Without it, parseEnvFile would
+// have to unescape every hand-authored env file it loads too, silently
+// mangling an ordinary value that happens to contain a literal `\n` (e.g.
+// a Windows path like `C:\new\file`) -- the escaping in escapeEnvValue is
+// a parry-to-parry round-trip format, not a general .env convention.
+const writeEnvMarker = "# parry:write-env"
+
+// escapeEnvValue escapes the characters that would otherwise corrupt or
+// truncate value when it's written as an env-file value and later read back
+// by parseEnvFile: a backslash (so the escapes below are themselves
+// unambiguous), an embedded newline (env files are one assignment per
+// physical line), and a `#` preceded by whitespace or at the start (which
+// stripInlineComment would otherwise read as an inline comment marker).
+// parseEnvFile's unescapeEnvValue reverses exactly this set on read, but
+// only for a file starting with writeEnvMarker.
+func escapeEnvValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	runes := []rune(value)
+	var b strings.Builder
+	for i, r := range runes {
+		if r == '#' && (i == 0 || runes[i-1] == ' ' || runes[i-1] == '\t') {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// writeEnvFile implements --write-env: it resolves every variable params
+// referenced against cfg directly (independent of any default/assignment
+// operator a particular occurrence used) and writes the result to path as
+// `NAME=value` lines, preceded by writeEnvMarker so parseEnvFile recognizes
+// the file as its own round-trip format. parry's own --envfile dialect
+// takes a value verbatim to end of line rather than stripping shell-style
+// quoting (see parseEnvFile), so values aren't wrapped in quotes here --
+// quoteForReuse's escaped quoting is for `${VAR@Q}`, producing a value
+// meant to be re-sourced by an actual shell, and would round-trip
+// incorrectly here. Instead, the handful of characters that would corrupt
+// a round trip through parseEnvFile are escaped via escapeEnvValue.
+func writeEnvFile(path string, params []Param, cfg *Config) error {
+	var b strings.Builder
+	b.WriteString(writeEnvMarker)
+	b.WriteByte('\n')
+	for _, name := range referencedNames(params) {
+		value, _ := cfg.lookupVar(name)
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(escapeEnvValue(value))
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
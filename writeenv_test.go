@@ -0,0 +1,70 @@
+package parry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetOutputWriteEnvRoundTripsThroughEnvfile(t *testing.T) {
+	os.Setenv("WRITEENV_HOST", "db01")
+	os.Setenv("WRITEENV_PORT", "5432")
+	defer os.Unsetenv("WRITEENV_HOST")
+	defer os.Unsetenv("WRITEENV_PORT")
+
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("$WRITEENV_HOST:$WRITEENV_PORT"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := filepath.Join(dir, "out.env")
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetWriteEnv(snapshot)
+	if got := captureStdout(t, func() { GetOutput(cfg) }); got != "db01:5432" {
+		t.Fatalf("got %q", got)
+	}
+
+	values, err := parseEnvFile(snapshot, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["WRITEENV_HOST"] != "db01" || values["WRITEENV_PORT"] != "5432" {
+		t.Fatalf("got %v", values)
+	}
+}
+
+func TestGetOutputWriteEnvRoundTripsValuesWithHashAndNewline(t *testing.T) {
+	os.Setenv("WRITEENV_TRICKY", "a # b\nsecond line")
+	defer os.Unsetenv("WRITEENV_TRICKY")
+
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "in.tmpl")
+	if err := os.WriteFile(tmpl, []byte("$WRITEENV_TRICKY"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := filepath.Join(dir, "out.env")
+
+	cfg := NewConfig()
+	cfg.AddFile(tmpl)
+	cfg.SetWriteEnv(snapshot)
+	captureStdout(t, func() { GetOutput(cfg) })
+
+	values, err := parseEnvFile(snapshot, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["WRITEENV_TRICKY"] != "a # b\nsecond line" {
+		t.Fatalf("got %q", values["WRITEENV_TRICKY"])
+	}
+}
+
+func TestReferencedNamesDedupesByNameInFirstAppearanceOrder(t *testing.T) {
+	params := []Param{{Name: "B"}, {Name: "A"}, {Name: "B"}}
+	got := referencedNames(params)
+	want := []string{"B", "A"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}